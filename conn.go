@@ -0,0 +1,211 @@
+package xbun
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+var _ bun.IConn = queryConn{}
+
+// queryConn is bun.IConn rewriting the executed queries according to the options applied to them (see SQLComment and StatementTimeout).
+// It's set to the query as its connection, so the rewritten queries are not visible to bun.QueryHook's.
+type queryConn struct {
+	conn bun.IConn
+
+	commented   bool
+	commentTags map[string]string
+
+	timeout        time.Duration
+	timeoutDialect dialect.Name
+}
+
+// wrapQueryConn sets queryConn modified by fn as the connection of bun.SelectQuery, bun.InsertQuery, bun.UpdateQuery,
+// bun.DeleteQuery or bun.RawQuery and reports whether the query type is supported.
+// If the query connection is already wrapped, the modified copy of the wrapper replaces it.
+func wrapQueryConn(q bun.Query, fn func(c *queryConn)) bool {
+	switch q := q.(type) {
+	case *bun.SelectQuery:
+		q.Conn(newQueryConn(q.GetConn(), fn))
+	case *bun.InsertQuery:
+		q.Conn(newQueryConn(q.GetConn(), fn))
+	case *bun.UpdateQuery:
+		q.Conn(newQueryConn(q.GetConn(), fn))
+	case *bun.DeleteQuery:
+		q.Conn(newQueryConn(q.GetConn(), fn))
+	case *bun.RawQuery:
+		q.Conn(newQueryConn(q.GetConn(), fn))
+	default:
+		return false
+	}
+
+	return true
+}
+
+func newQueryConn(conn bun.IConn, fn func(c *queryConn)) queryConn {
+	c, ok := conn.(queryConn)
+	if !ok {
+		c = queryConn{conn: conn}
+	}
+
+	fn(&c)
+
+	return c
+}
+
+// comment enables the sqlcommenter comment with the given tags merged into the ones set before.
+func (c *queryConn) comment(tags map[string]string) {
+	c.commented = true
+
+	if len(tags) > 0 {
+		merged := maps.Clone(c.commentTags)
+		if merged == nil {
+			merged = make(map[string]string, len(tags))
+		}
+
+		maps.Copy(merged, tags)
+		c.commentTags = merged
+	}
+}
+
+func (c queryConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	query, err := c.rewrite(ctx, query, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.conn.QueryContext(ctx, query, args...)
+}
+
+// ExecContext restores the statement timeout with a separate statement, since the result of the multi-statement query
+// is the one of its last statement.
+func (c queryConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, err := c.rewrite(ctx, query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.conn.ExecContext(ctx, query, args...)
+	if err == nil && c.restoresTimeout() {
+		_, err = c.conn.ExecContext(ctx, pgRestoreStatementTimeout)
+	}
+
+	return res, err
+}
+
+func (c queryConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	query, err := c.rewrite(ctx, query, true)
+	if err != nil {
+		return errRow(ctx, err)
+	}
+
+	return c.conn.QueryRowContext(ctx, query, args...)
+}
+
+// rewrite returns the query with the statement timeout and the comment applied.
+// If restore is set, the statement restoring the timeout is appended to the query when necessary (see restoresTimeout).
+func (c queryConn) rewrite(ctx context.Context, query string, restore bool) (string, error) {
+	if c.timeout > 0 {
+		var err error
+		if query, err = applyStatementTimeout(query, c.timeout, c.timeoutDialect, c.restoresTimeout()); err != nil {
+			return "", err
+		}
+	}
+
+	if c.commented {
+		if comment := FormatSQLComment(c.commentTagsFor(ctx)); comment != "" {
+			query += " " + comment
+		}
+	}
+
+	if restore && c.restoresTimeout() {
+		query += "; " + pgRestoreStatementTimeout
+	}
+
+	return query, nil
+}
+
+// errRow returns *sql.Row failing with the given error, since it can't be constructed otherwise:
+// the row is queried from the database which connector fails with the error.
+func errRow(ctx context.Context, err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err: err})
+	defer db.Close()
+
+	return db.QueryRowContext(ctx, "")
+}
+
+// errConnector is driver.Connector failing to connect with the error.
+type errConnector struct {
+	err error
+}
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) { return nil, c.err }
+func (c errConnector) Driver() driver.Driver                        { return nil }
+
+// restoresTimeout reports whether the PostgreSQL statement timeout must be restored after the query.
+// It's the case unless the query is run with *sql.DB, i.e. in its own implicit transaction, which is the scope of `SET LOCAL`;
+// otherwise, the query may be a part of the transaction, which the timeout would outlive.
+func (c queryConn) restoresTimeout() bool {
+	if c.timeout <= 0 || c.timeoutDialect != dialect.PG {
+		return false
+	}
+
+	_, ok := c.conn.(*sql.DB)
+
+	return !ok
+}
+
+// commentTagsFor returns the tags of SQLCommentHook, overridden by the ones of the context, overridden by the ones of the query.
+func (c queryConn) commentTagsFor(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+
+	if hook := SQLCommentHook(); hook != nil {
+		maps.Copy(tags, hook(ctx))
+	}
+
+	maps.Copy(tags, SQLCommentFromContext(ctx))
+	maps.Copy(tags, c.commentTags)
+
+	return tags
+}
+
+// PostgreSQL statements saving the current statement timeout to the custom transaction-local setting and restoring it from there.
+// They don't return rows, so the multi-statement query still has the only result set of the actual query.
+const (
+	pgSaveStatementTimeout    = `DO $$BEGIN PERFORM set_config('xbun.statement_timeout', current_setting('statement_timeout'), true); END$$`
+	pgRestoreStatementTimeout = `DO $$BEGIN PERFORM set_config('statement_timeout', current_setting('xbun.statement_timeout'), true); END$$`
+)
+
+// applyStatementTimeout returns the query bounded by the timeout with the dialect's mechanism (see StatementTimeout)
+// or xerr.QueryOptionError if the query can't be bounded.
+// With PostgreSQL, the current timeout is saved first if save is set, so it could be restored after the query.
+func applyStatementTimeout(query string, timeout time.Duration, name dialect.Name, save bool) (string, error) {
+	ms := strconv.FormatInt(max(timeout.Milliseconds(), 1), 10)
+
+	switch name {
+	case dialect.PG:
+		query = "SET LOCAL statement_timeout = " + ms + "; " + query
+		if save {
+			query = pgSaveStatementTimeout + "; " + query
+		}
+
+		return query, nil
+	case dialect.MySQL:
+		if len(query) >= 6 && strings.EqualFold(query[:6], "SELECT") {
+			return query[:6] + " /*+ MAX_EXECUTION_TIME(" + ms + ") */" + query[6:], nil
+		}
+
+		return "", xerr.ErrQueryOption("StatementTimeout", "the query must start with SELECT with "+name.String())
+	default:
+		return "", xerr.ErrQueryOption("StatementTimeout", "unsupported dialect "+name.String())
+	}
+}
@@ -8,6 +8,15 @@ const (
 	OrderDesc OrderDir = "DESC"
 )
 
+// NullsOrder is the placement of NULL values in sorting.
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""
+	NullsFirst   NullsOrder = "NULLS FIRST"
+	NullsLast    NullsOrder = "NULLS LAST"
+)
+
 // Sub-condition separator constraints that you can use in bun's WhereGroup calls.
 const (
 	SepAND = " AND "
@@ -84,13 +84,13 @@ func AffectedGTE[Int constraints.Integer](expected Int) AffectedCond {
 // -----------------------------------------------------------------------------------------------------------------------------------------
 
 // ExpectSuccess checks if the query returns no error.
-// If the query returns an error, it returns the error wrapped in a xerr.QueryExecutionError.
+// If the query returns an error, it returns the error classified with xerr.Classify and wrapped in a xerr.QueryExecutionError.
 // If the query returns sql.ErrNoRows, it works like AffectedNot(0)(0) ie returns an xerr.AffectedRowsError.
 func ExpectSuccess(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return AffectedNot(0)(0)
 	} else if err != nil {
-		return xerr.ErrQueryExecution(err)
+		return xerr.ErrQueryExecution(xerr.Classify(err))
 	}
 
 	return nil
@@ -102,7 +102,7 @@ func ExpectSuccess(err error) error {
 // If any of the conditions is not met, it returns a corresponding xerr.AffectedRowsError for the first mismatch.
 //
 // If sql.ErrNoRows passed as an err, it is being omitted and further check is performed as for zero-row result.
-// For any other error, it returns an error wrapped in a xerr.QueryExecutionError.
+// For any other error, it returns an error classified with xerr.Classify and wrapped in a xerr.QueryExecutionError.
 //
 // Note that underlying RowsAffected() call on sql.Result may not be supported by the driver, so it will cause a specific error.
 func ExpectResult(result sql.Result, err error, cond ...AffectedCond) error {
@@ -123,7 +123,7 @@ func ExpectResult(result sql.Result, err error, cond ...AffectedCond) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return checkConditions(0)
 	} else if err != nil {
-		return xerr.ErrQueryExecution(err)
+		return xerr.ErrQueryExecution(xerr.Classify(err))
 	}
 
 	actual, rowsErr := result.RowsAffected()
@@ -104,12 +104,18 @@ func (d dummyResult) RowsAffected() (int64, error) {
 	return d.affected, d.err
 }
 
+type testSQLStateError string
+
+func (e testSQLStateError) Error() string    { return "sqlstate " + string(e) }
+func (e testSQLStateError) SQLState() string { return string(e) }
+
 func TestExpectSuccess(t *testing.T) {
 	t.Parallel()
 
 	require.NoError(t, ExpectSuccess(nil))
 	require.ErrorAs(t, ExpectSuccess(sql.ErrNoRows), &xerr.AffectedRowsError{})
 	require.ErrorAs(t, ExpectSuccess(errors.New("")), &xerr.QueryExecutionError{})
+	require.ErrorAs(t, ExpectSuccess(testSQLStateError(xerr.SQLStateLockNotAvailable)), &xerr.LockNotAvailableError{})
 }
 
 func TestExpectResult(t *testing.T) {
@@ -140,6 +146,7 @@ func TestExpectResult(t *testing.T) {
 	t.Run("query error", func(t *testing.T) {
 		t.Parallel()
 		require.ErrorAs(t, ExpectResult(dummyResult{}, errors.New("")), &xerr.QueryExecutionError{})
+		require.ErrorAs(t, ExpectResult(dummyResult{}, testSQLStateError(xerr.SQLStateLockNotAvailable), AffectedExactly(1)), &xerr.LockNotAvailableError{})
 	})
 
 	t.Run("affected rows error", func(t *testing.T) {
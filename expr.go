@@ -1,14 +1,27 @@
 package xbun
 
+import "github.com/heffcodex/xbun/xerr"
+
 // OrderExpr unifies the construction of ORDER BY expressions.
 // If direction is not specified, it defaults to OrderAsc.
+// It panics on an invalid direction, see SafeOrderExpr for the directions coming from the outside.
 func OrderExpr(field string, dir OrderDir) string {
+	expr, err := SafeOrderExpr(field, dir)
+	if err != nil {
+		panic("invalid order direction") // this should never happen
+	}
+
+	return expr
+}
+
+// SafeOrderExpr works just like OrderExpr, but returns a xerr.SortError on an invalid direction instead of panicking.
+func SafeOrderExpr(field string, dir OrderDir) (string, error) {
 	switch dir {
 	case "":
-		return field + " " + string(OrderAsc)
+		return field + " " + string(OrderAsc), nil
 	case OrderAsc, OrderDesc:
-		return field + " " + string(dir)
+		return field + " " + string(dir), nil
 	default:
-		panic("invalid order direction") // this should never happen
+		return "", xerr.ErrSort(field, "invalid order direction "+string(dir))
 	}
 }
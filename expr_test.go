@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun/xerr"
 )
 
 func TestOrderExpr(t *testing.T) {
@@ -14,14 +16,32 @@ func TestOrderExpr(t *testing.T) {
 		dir   OrderDir
 		want  string
 	}{
+		{"field", "", "field ASC"},
 		{"field", OrderAsc, "field ASC"},
 		{"field", OrderDesc, "field DESC"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.field, func(t *testing.T) {
+		t.Run(tt.field+" "+string(tt.dir), func(t *testing.T) {
 			t.Parallel()
+
 			require.Equal(t, tt.want, OrderExpr(tt.field, tt.dir))
+
+			got, err := SafeOrderExpr(tt.field, tt.dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestSafeOrderExpr_invalid(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() { OrderExpr("field", "SIDEWAYS") })
+
+	_, err := SafeOrderExpr("field", "SIDEWAYS")
+
+	sortErr := xerr.SortError{}
+	require.ErrorAs(t, err, &sortErr)
+	require.Equal(t, "field", sortErr.Field())
+}
@@ -4,9 +4,13 @@ go 1.22
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/oklog/ulid/v2 v2.1.0
 	github.com/pierrec/xxHash v0.1.5
 	github.com/stretchr/testify v1.9.0
 	github.com/uptrace/bun v1.2.1
+	github.com/uptrace/bun/dialect/mysqldialect v1.2.1
+	github.com/uptrace/bun/dialect/pgdialect v1.2.1
+	github.com/uptrace/bun/dialect/sqlitedialect v1.2.1
 	golang.org/x/exp v0.0.0-20240707233637-46b078467d37
 )
 
@@ -18,6 +22,7 @@ require (
 	github.com/tmthrgd/go-hex v0.0.0-20190904060850-447a3041c3bc // indirect
 	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/mod v0.19.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
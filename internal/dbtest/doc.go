@@ -0,0 +1,3 @@
+// Package dbtest contains the tests of xbun packages running against a real database with xbuntest.
+// It's a separate module, so the database drivers required by xbuntest are not the dependencies of xbun itself.
+package dbtest
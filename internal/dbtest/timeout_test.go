@@ -0,0 +1,95 @@
+package dbtest
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xerr"
+	"github.com/heffcodex/xbun/xquery"
+)
+
+// testTimeout is the statement timeout of the tests, which slowQuery outlasts.
+const (
+	testTimeout = 100 * time.Millisecond
+	slowQuery   = "SELECT pg_sleep(0.3)::text"
+)
+
+func skipWithoutPostgres(t *testing.T) {
+	t.Helper()
+
+	if os.Getenv(xbuntest.EnvPostgresDSN) == "" {
+		t.Skip("PostgreSQL is required, set " + xbuntest.EnvPostgresDSN)
+	}
+}
+
+func showStatementTimeout(ctx context.Context, t *testing.T, db bun.IDB) string {
+	t.Helper()
+
+	var timeout string
+	require.NoError(t, db.NewRaw("SHOW statement_timeout").Scan(ctx, &timeout))
+
+	return timeout
+}
+
+func TestStatementTimeout(t *testing.T) {
+	skipWithoutPostgres(t)
+	t.Parallel()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testNote)(nil)))
+	ctx := context.Background()
+	timeout := xbun.StatementTimeout(testTimeout)
+	before := showStatementTimeout(ctx, t, db)
+
+	// The multi-statement queries are accepted by pgdriver and keep the results of the actual query.
+	var n int
+	require.NoError(t, xbun.QueryOptions(db.NewRaw("SELECT 1"), timeout).Scan(ctx, &n))
+	require.Equal(t, 1, n)
+	require.Equal(t, before, showStatementTimeout(ctx, t, db))
+
+	_, err := db.NewInsert().Model(&testNote{PK: xbun.PK[int64]{ID: 1}, Text: "a"}).Exec(ctx)
+	require.NoError(t, err)
+
+	res, err := xbun.QueryOptions(db.NewDelete().Model((*testNote)(nil)).Where("1 = 1"), timeout).Exec(ctx)
+	require.NoError(t, xbun.ExpectResult(res, err, xbun.AffectedExactly(1)))
+	require.Equal(t, before, showStatementTimeout(ctx, t, db))
+
+	// The slow query fails with the timeout, which doesn't bound the following queries of the transaction.
+	err = xbun.RunInTx(ctx, db.Tx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return xbun.ExpectSuccess(xbun.QueryOptions(tx.NewRaw(slowQuery), timeout).Scan(ctx, new(string)))
+	})
+	require.True(t, xerr.IsStatementTimeout(err), err)
+
+	require.NoError(t, db.NewRaw(slowQuery).Scan(ctx, new(string)))
+	require.Equal(t, before, showStatementTimeout(ctx, t, db))
+
+	// Outside of a transaction, the timeout is scoped by the implicit transaction of the query.
+	err = xbun.ExpectSuccess(xbun.QueryOptions(db.Base.NewRaw(slowQuery), timeout).Scan(ctx, new(string)))
+	require.True(t, xerr.IsStatementTimeout(err), err)
+
+	require.NoError(t, db.Base.NewRaw(slowQuery).Scan(ctx, new(string)))
+}
+
+func TestSelect_ChunkTimeout(t *testing.T) {
+	skipWithoutPostgres(t)
+	t.Parallel()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testArticle)(nil)))
+	ctx := db.Context(context.Background())
+
+	_, err := db.NewInsert().Model(&[]*testArticle{{PK: xbun.PK[int64]{ID: 1}}, {PK: xbun.PK[int64]{ID: 2}}}).Exec(ctx)
+	require.NoError(t, err)
+
+	s := &xquery.Select[int64, *testArticle, []*testArticle]{NativeCursorIter: true, ChunkTimeout: testTimeout}
+
+	err = s.Iter(ctx, db.Base, 1, func(ctx context.Context, db bun.IDB, _ []*testArticle) (bool, error) {
+		return true, db.NewRaw(slowQuery).Scan(ctx, new(string))
+	})
+	require.NoError(t, err)
+}
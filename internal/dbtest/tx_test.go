@@ -0,0 +1,89 @@
+package dbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+)
+
+func TestRunInTx_foreignTx(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t)
+	noop := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		db   bun.IDB
+	}{
+		{"given directly", context.Background(), db.Tx},
+		{"ambient", xbun.ContextWithTx(context.Background(), db.Tx), db.Base},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := xbun.RunInTx(tt.ctx, tt.db, nil, func(ctx context.Context, _ bun.Tx) error {
+				require.Error(t, xbun.OnCommit(ctx, noop))
+				require.Error(t, xbun.OnRollback(ctx, noop))
+
+				return xbun.RunInTx(ctx, tt.db, nil, func(ctx context.Context, _ bun.Tx) error {
+					require.Error(t, xbun.OnCommit(ctx, noop))
+					return nil
+				})
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunInTx_detachedTx(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t)
+	ctx := xbun.ContextWithDetachedTx(context.Background(), db.Tx)
+
+	var calls []string
+
+	err := xbun.RunInTx(ctx, db.Base, nil, func(ctx context.Context, _ bun.Tx) error {
+		return xbun.OnCommit(ctx, func(context.Context) error {
+			calls = append(calls, "commit")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"commit"}, calls)
+}
+
+func TestRunInTx_panic(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t)
+
+	var calls []string
+
+	hook := func(name string) xbun.TxHookFunc {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	require.PanicsWithValue(t, "boom", func() {
+		_ = xbun.RunInTx(context.Background(), db.Base, nil, func(ctx context.Context, _ bun.Tx) error {
+			require.NoError(t, xbun.OnCommit(ctx, hook("commit")))
+			require.NoError(t, xbun.OnRollback(ctx, hook("rollback")))
+
+			return xbun.RunInTx(ctx, db.Base, nil, func(ctx context.Context, _ bun.Tx) error {
+				require.NoError(t, xbun.OnRollback(ctx, hook("nested rollback")))
+				panic("boom")
+			})
+		})
+	})
+	require.Equal(t, []string{"rollback", "nested rollback"}, calls)
+}
@@ -0,0 +1,143 @@
+package dbtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xlog"
+)
+
+type testAccount struct {
+	bun.BaseModel `bun:"table:accounts,alias:a"`
+
+	xbun.PK[int64]
+
+	Email    string `bun:"email,notnull"`
+	Password string `bun:"password,notnull" xbun:"redact"`
+}
+
+// logBuffer collects the JSON log records.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func testLogHook(t *testing.T, hook *xlog.Hook) (xbuntest.DB, *logBuffer) {
+	t.Helper()
+
+	buf := &logBuffer{}
+	hook.Logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testAccount)(nil)))
+	db.Base.AddQueryHook(hook)
+
+	return db, buf
+}
+
+func TestLogHook(t *testing.T) {
+	t.Parallel()
+
+	db, buf := testLogHook(t, &xlog.Hook{Level: slog.LevelDebug})
+	ctx := context.Background()
+
+	_, err := db.NewInsert().Model(&testAccount{PK: xbun.PK[int64]{ID: 1}, Email: "a@example.com", Password: "s3cret"}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewRaw("SELECT * FROM missing").Exec(ctx)
+	require.Error(t, err)
+
+	records := buf.records(t)
+	require.Len(t, records, 2)
+
+	insert := records[0]
+	require.Equal(t, "DEBUG", insert["level"])
+	require.Equal(t, "xbun: query", insert["msg"])
+	require.Equal(t, "INSERT", insert["operation"])
+	require.Equal(t, "accounts", insert["table"])
+	require.EqualValues(t, 1, insert["rows_affected"])
+	require.Contains(t, insert["query"], "'a@example.com'")
+	require.Contains(t, insert["query"], xlog.RedactedValue)
+	require.NotContains(t, insert["query"], "s3cret")
+	require.Contains(t, insert["caller"], "xlog_test.go:")
+
+	failed := records[1]
+	require.Equal(t, "ERROR", failed["level"])
+	require.Equal(t, "other", failed["error_class"])
+	require.Contains(t, failed["error"], "missing")
+}
+
+func TestLogHook_slow(t *testing.T) {
+	t.Parallel()
+
+	db, buf := testLogHook(t, &xlog.Hook{SlowThreshold: time.Nanosecond, Explain: true})
+
+	_, err := db.Base.NewSelect().Model((*testAccount)(nil)).Where("id = ?", 1).Exists(context.Background())
+	require.NoError(t, err)
+
+	records := buf.records(t)
+	require.Len(t, records, 1)
+	require.Equal(t, "WARN", records[0]["level"])
+	require.Equal(t, "xbun: slow query", records[0]["msg"])
+	require.NotEmpty(t, records[0]["explain"])
+}
+
+func TestLogHook_sample(t *testing.T) {
+	t.Parallel()
+
+	db, buf := testLogHook(t, &xlog.Hook{Sample: xlog.SampleEvery(2)})
+	ctx := context.Background()
+
+	for id := range 3 {
+		_, err := db.NewSelect().Model((*testAccount)(nil)).Where("id = ?", id).Count(ctx)
+		require.NoError(t, err)
+	}
+
+	_, err := db.NewSelect().Model((*testAccount)(nil)).Where("email = ?", "a").Count(ctx)
+	require.NoError(t, err)
+
+	records := buf.records(t)
+	require.Len(t, records, 3)
+	require.Contains(t, records[0]["query"], "id")
+	require.Contains(t, records[1]["query"], "id")
+	require.Contains(t, records[2]["query"], "email")
+}
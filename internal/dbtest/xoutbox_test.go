@@ -0,0 +1,140 @@
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xoutbox"
+)
+
+// testRelayDB returns the test database with the messages of topics "a", "b" and "c" enqueued in that order.
+func testRelayDB(t *testing.T) (xbuntest.DB, context.Context) {
+	t.Helper()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*xoutbox.Message)(nil)))
+	ctx := db.Context(context.Background())
+
+	for _, topic := range []string{"a", "b", "c"} {
+		_, err := xoutbox.Enqueue(ctx, db.Base, topic, map[string]string{"topic": topic})
+		require.NoError(t, err)
+	}
+
+	return db, ctx
+}
+
+func topics(msgs []*xoutbox.Message) []string {
+	result := make([]string, len(msgs))
+	for i, msg := range msgs {
+		result[i] = msg.Topic
+	}
+
+	return result
+}
+
+func outboxMessages(ctx context.Context, t *testing.T, db xbuntest.DB) []*xoutbox.Message {
+	t.Helper()
+
+	var msgs []*xoutbox.Message
+	require.NoError(t, db.NewSelect().Model(&msgs).Order("topic").Scan(ctx))
+
+	return msgs
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testRelayDB(t)
+	pub := &xoutbox.MemoryPublisher{}
+	relay := &xoutbox.Relay{Publisher: pub, BatchSize: 2}
+
+	for _, want := range []int{2, 1, 0} {
+		n, err := relay.RelayOnce(ctx, db.Base)
+		require.NoError(t, err)
+		require.Equal(t, want, n)
+	}
+
+	require.Equal(t, []string{"a", "b", "c"}, topics(pub.Published()))
+
+	for _, msg := range outboxMessages(ctx, t, db) {
+		require.False(t, msg.SentAt.IsZero(), msg.Topic)
+		require.Zero(t, msg.Attempts, msg.Topic)
+	}
+}
+
+func TestRelay_retry(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testRelayDB(t)
+	errFail := errors.New("fail")
+
+	pub := &xoutbox.MemoryPublisher{
+		FailFunc: func(msg *xoutbox.Message) error {
+			if msg.Topic == "b" {
+				return errFail
+			}
+
+			return nil
+		},
+	}
+
+	relay := &xoutbox.Relay{
+		Publisher: pub,
+		Backoff:   func(attempts int) time.Duration { return time.Duration(attempts) * time.Hour },
+	}
+
+	start := time.Now().UTC()
+
+	n, err := relay.RelayOnce(ctx, db.Base)
+	require.ErrorIs(t, err, errFail)
+	require.Equal(t, 3, n)
+	require.Equal(t, []string{"a"}, topics(pub.Published()))
+
+	for _, msg := range outboxMessages(ctx, t, db) {
+		require.True(t, msg.SentAt.IsZero(), msg.Topic)
+		require.Equal(t, 1, msg.Attempts, msg.Topic)
+		require.WithinDuration(t, start.Add(time.Hour), msg.RunAt, time.Minute, msg.Topic)
+	}
+
+	// The failed batch is not due until the backoff passes.
+	n, err = relay.RelayOnce(ctx, db.Base)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	_, err = db.NewUpdate().Model((*xoutbox.Message)(nil)).Set("run_at = ?", start.Add(-time.Minute)).Where("1 = 1").Exec(ctx)
+	require.NoError(t, err)
+
+	pub.Reset()
+	pub.FailFunc = nil
+
+	n, err = relay.RelayOnce(ctx, db.Base)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []string{"a", "b", "c"}, topics(pub.Published()))
+
+	for _, msg := range outboxMessages(ctx, t, db) {
+		require.False(t, msg.SentAt.IsZero(), msg.Topic)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*xoutbox.Message)(nil)))
+	ctx := db.Context(context.Background())
+
+	msg1, err := xoutbox.Enqueue(ctx, db.Base, "a", nil)
+	require.NoError(t, err)
+
+	msg2, err := xoutbox.Enqueue(ctx, db.Base, "a", nil)
+	require.NoError(t, err)
+
+	// IDs are assigned by the database rather than generated by the process.
+	require.Positive(t, msg1.ID)
+	require.Greater(t, msg2.ID, msg1.ID)
+	require.Equal(t, msg1.CreatedAt.Time, msg1.RunAt)
+}
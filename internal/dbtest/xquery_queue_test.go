@@ -0,0 +1,238 @@
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xquery"
+)
+
+type testJob struct {
+	bun.BaseModel `bun:"table:jobs,alias:j"`
+
+	xbun.PK[int64]
+
+	RunAt    time.Time    `bun:"run_at,notnull"`
+	Attempts int          `bun:"attempts,notnull"`
+	DoneAt   bun.NullTime `bun:"done_at,nullzero"`
+}
+
+// testQueueDB returns the test database with the jobs due in the order of 2, 3, 1 and the job 4 not due yet.
+func testQueueDB(t *testing.T) (xbuntest.DB, context.Context) {
+	t.Helper()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testJob)(nil), (*testNote)(nil)))
+	ctx := db.Context(context.Background())
+	now := time.Now().UTC()
+
+	jobs := []*testJob{
+		{PK: xbun.PK[int64]{ID: 1}, RunAt: now.Add(-time.Minute)},
+		{PK: xbun.PK[int64]{ID: 2}, RunAt: now.Add(-3 * time.Minute)},
+		{PK: xbun.PK[int64]{ID: 3}, RunAt: now.Add(-2 * time.Minute)},
+		{PK: xbun.PK[int64]{ID: 4}, RunAt: now.Add(time.Hour)},
+	}
+
+	_, err := db.NewInsert().Model(&jobs).Exec(ctx)
+	require.NoError(t, err)
+
+	return db, ctx
+}
+
+func jobIDs(jobs []*testJob) []int64 {
+	ids := make([]int64, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+
+	return ids
+}
+
+func TestQueue_Claim(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+
+		db, ctx := testQueueDB(t)
+		q := &xquery.Queue[*testJob, []*testJob]{BatchSize: 2}
+
+		var claimed [][]int64
+
+		handler := func(_ context.Context, _ bun.IDB, jobs []*testJob) error {
+			claimed = append(claimed, jobIDs(jobs))
+			return nil
+		}
+
+		for _, want := range []int{2, 1, 0} {
+			n, err := q.Claim(ctx, db, handler)
+			require.NoError(t, err)
+			require.Equal(t, want, n)
+		}
+
+		require.Equal(t, [][]int64{{2, 3}, {1}}, claimed)
+
+		var left []*testJob
+		require.NoError(t, db.NewSelect().Model(&left).Scan(ctx))
+		require.Equal(t, []int64{4}, jobIDs(left))
+	})
+
+	t.Run("mark done", func(t *testing.T) {
+		t.Parallel()
+
+		db, ctx := testQueueDB(t)
+		q := &xquery.Queue[*testJob, []*testJob]{OnSuccess: xquery.QueueMarkDone}
+
+		n, err := q.Claim(ctx, db, func(context.Context, bun.IDB, []*testJob) error { return nil })
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+
+		n, err = q.Claim(ctx, db, func(context.Context, bun.IDB, []*testJob) error { return nil })
+		require.NoError(t, err)
+		require.Zero(t, n)
+
+		var jobs []*testJob
+		require.NoError(t, db.NewSelect().Model(&jobs).Order("id").Scan(ctx))
+		require.Len(t, jobs, 4)
+
+		for _, job := range jobs[:3] {
+			require.False(t, job.DoneAt.IsZero(), "job %d", job.ID)
+		}
+
+		require.True(t, jobs[3].DoneAt.IsZero())
+	})
+}
+
+func TestQueue_Claim_handlerError(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testQueueDB(t)
+	errHandler := errors.New("handler")
+
+	q := &xquery.Queue[*testJob, []*testJob]{
+		Backoff: func(attempts int) time.Duration { return time.Duration(attempts) * time.Hour },
+	}
+
+	handler := func(ctx context.Context, tx bun.IDB, _ []*testJob) error {
+		_, err := tx.NewInsert().Model(&testNote{PK: xbun.PK[int64]{ID: 1}, Text: "rolled back"}).Exec(ctx)
+		require.NoError(t, err)
+
+		return errHandler
+	}
+
+	start := time.Now().UTC()
+
+	n, err := q.Claim(ctx, db, handler)
+	require.ErrorIs(t, err, errHandler)
+	require.Equal(t, 3, n)
+
+	notes, err := db.NewSelect().Model((*testNote)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Zero(t, notes, "handler changes must be rolled back")
+
+	var jobs []*testJob
+	require.NoError(t, db.NewSelect().Model(&jobs).Order("id").Scan(ctx))
+
+	for _, job := range jobs[:3] {
+		require.Equal(t, 1, job.Attempts, "job %d", job.ID)
+		require.WithinDuration(t, start.Add(time.Hour), job.RunAt, time.Minute, "job %d", job.ID)
+	}
+
+	require.Zero(t, jobs[3].Attempts)
+
+	// The rescheduled jobs are not due anymore.
+	n, err = q.Claim(ctx, db, handler)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestQueue_Run(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testQueueDB(t)
+	ctx, cancel := context.WithCancel(ctx)
+
+	q := &xquery.Queue[*testJob, []*testJob]{BatchSize: 1, PollInterval: time.Millisecond}
+
+	var handled atomic.Int64
+
+	done := make(chan error)
+
+	go func() {
+		done <- q.Run(ctx, db, 1, func(_ context.Context, _ bun.IDB, jobs []*testJob) error {
+			if handled.Add(int64(len(jobs))) == 3 {
+				cancel()
+			}
+
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run has not stopped on the context cancellation")
+	}
+
+	require.EqualValues(t, 3, handled.Load())
+
+	left, err := db.NewSelect().Model((*testJob)(nil)).Count(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, left)
+}
+
+func TestQueue_Claim_handlerPanic(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testQueueDB(t)
+	q := &xquery.Queue[*testJob, []*testJob]{}
+
+	n, err := q.Claim(ctx, db, func(context.Context, bun.IDB, []*testJob) error { panic("boom") })
+	require.ErrorContains(t, err, "boom")
+	require.Equal(t, 3, n)
+
+	var jobs []*testJob
+	require.NoError(t, db.NewSelect().Model(&jobs).Order("id").Scan(ctx))
+
+	for _, job := range jobs[:3] {
+		require.Equal(t, 1, job.Attempts, "job %d", job.ID)
+	}
+}
+
+func TestQueue_Claim_canceled(t *testing.T) {
+	t.Parallel()
+
+	db, ctx := testQueueDB(t)
+	ctx, cancel := context.WithCancel(ctx)
+	q := &xquery.Queue[*testJob, []*testJob]{}
+
+	n, err := q.Claim(ctx, db, func(ctx context.Context, _ bun.IDB, _ []*testJob) error {
+		cancel()
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 3, n)
+
+	// The claim is rolled back, so the attempts are not spent.
+	var jobs []*testJob
+	require.NoError(t, db.NewSelect().Model(&jobs).Order("id").Scan(context.Background()))
+	require.Len(t, jobs, 4)
+
+	for _, job := range jobs {
+		require.Zero(t, job.Attempts, "job %d", job.ID)
+	}
+
+	n, err = q.Claim(ctx, db, func(context.Context, bun.IDB, []*testJob) error { return nil })
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, n)
+}
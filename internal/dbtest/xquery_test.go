@@ -0,0 +1,71 @@
+package dbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xquery"
+	"github.com/heffcodex/xbun/xtrace"
+)
+
+type testArticle struct {
+	bun.BaseModel `bun:"table:articles,alias:a"`
+
+	xbun.PK[int64]
+
+	Title string `bun:"title"`
+}
+
+func TestSelect_trace(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testArticle)(nil)))
+	rec := xtrace.NewRecorder()
+	ctx := rec.Context(db.Context(context.Background()))
+
+	articles := make([]*testArticle, 5)
+	for i := range articles {
+		articles[i] = &testArticle{PK: xbun.PK[int64]{ID: int64(i + 1)}}
+	}
+
+	_, err := db.NewInsert().Model(&articles).Exec(ctx)
+	require.NoError(t, err)
+
+	s := &xquery.Select[int64, *testArticle, []*testArticle]{}
+
+	err = s.Iter(ctx, db, 2, func(context.Context, bun.IDB, []*testArticle) (bool, error) { return true, nil })
+	require.NoError(t, err)
+
+	spans := rec.Spans(xtrace.SpanIter)
+	require.Len(t, spans, 1)
+	require.True(t, spans[0].Ended)
+	require.Equal(t, map[string]any{
+		xtrace.AttrIterCursor:    xtrace.CursorSoft,
+		xtrace.AttrIterChunkSize: 2,
+		xtrace.AttrDBTable:       "articles",
+		xtrace.AttrIterChunks:    3,
+		xtrace.AttrIterRows:      5,
+	}, spans[0].Attrs)
+
+	require.InDelta(t, 3, rec.Sum(xtrace.MetricIterChunks), 0)
+	require.InDelta(t, 5, rec.Sum(xtrace.MetricIterChunkRows), 0)
+
+	_, err = s.Paginate(ctx, db, 3, 2)
+	require.NoError(t, err)
+
+	spans = rec.Spans(xtrace.SpanPaginate)
+	require.Len(t, spans, 1)
+	require.Equal(t, map[string]any{
+		xtrace.AttrPaginatePage:          uint(3),
+		xtrace.AttrPaginatePerPage:       uint(2),
+		xtrace.AttrDBTable:               "articles",
+		xtrace.AttrPaginateTotal:         uint(5),
+		xtrace.AttrPaginateTotalPages:    uint(3),
+		xtrace.AttrPaginateEffectivePage: uint(3),
+	}, spans[0].Attrs)
+}
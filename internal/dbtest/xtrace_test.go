@@ -0,0 +1,58 @@
+package dbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xbuntest"
+	"github.com/heffcodex/xbun/xtrace"
+)
+
+type testNote struct {
+	bun.BaseModel `bun:"table:notes,alias:n"`
+
+	xbun.PK[int64]
+
+	Text string `bun:"text,notnull"`
+}
+
+func TestTraceHook(t *testing.T) {
+	t.Parallel()
+
+	db := xbuntest.New(t, xbuntest.WithModels((*testNote)(nil)))
+	db.Base.AddQueryHook(xtrace.Hook{})
+
+	rec := xtrace.NewRecorder()
+	ctx, parent := rec.Start(rec.Context(context.Background()), "parent")
+
+	_, err := db.NewInsert().Model(&testNote{PK: xbun.PK[int64]{ID: 1}, Text: "secret"}).Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.NewRaw("SELECT * FROM missing").Exec(ctx)
+	require.Error(t, err)
+
+	parent.End()
+
+	spans := rec.Spans(xtrace.SpanQuery)
+	require.Len(t, spans, 2)
+
+	insert := spans[0]
+	require.True(t, insert.Ended)
+	require.Equal(t, "parent", insert.Parent.Name)
+	require.Equal(t, `INSERT INTO "notes" ("text", "id") VALUES (?)`, insert.Attrs[xtrace.AttrDBStatement])
+	require.Equal(t, "INSERT", insert.Attrs[xtrace.AttrDBOperation])
+	require.Equal(t, "notes", insert.Attrs[xtrace.AttrDBTable])
+	require.EqualValues(t, 1, insert.Attrs[xtrace.AttrDBRows])
+	require.Empty(t, insert.Errors)
+
+	failed := spans[1]
+	require.Len(t, failed.Errors, 1)
+	require.NotContains(t, failed.Attrs, xtrace.AttrDBTable)
+
+	require.Len(t, rec.Metrics(xtrace.MetricQueryDuration), 2)
+	require.InDelta(t, 1, rec.Sum(xtrace.MetricQueryErrors), 0)
+}
@@ -1,7 +1,15 @@
 package xbun
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
 	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/uptrace/bun"
 	"golang.org/x/exp/constraints"
 )
 
@@ -9,11 +17,29 @@ var (
 	_ HasPK[string]    = (*PK[string])(nil)
 	_ HasPK[int]       = (*PKAutoIncrement[int])(nil)
 	_ HasPK[uuid.UUID] = (*PKUUID)(nil)
+	_ HasPK[uuid.UUID] = (*PKUUIDv7)(nil)
+	_ HasPK[ULID]      = (*PKULID)(nil)
+	_ HasPK[int64]     = (*PKSnowflake)(nil)
+
+	_ bun.BeforeInsertHook = (*PKUUIDv7)(nil)
+	_ bun.BeforeInsertHook = (*PKULID)(nil)
+	_ idGenerator          = (*PKUUIDv7)(nil)
+	_ bun.BeforeInsertHook = (*PKSnowflake)(nil)
+	_ idGenerator          = (*PKULID)(nil)
+	_ idGenerator          = (*PKSnowflake)(nil)
+
+	_ driver.Valuer = ULID{}
+	_ sql.Scanner   = (*ULID)(nil)
 )
 
 type (
+	// idGenerator is implemented by the primary key mixins generating the ID on insert if it's not set yet.
+	idGenerator interface {
+		generateID() error
+	}
+
 	IID interface {
-		constraints.Ordered | uuid.UUID
+		constraints.Ordered | uuid.UUID | ULID
 	}
 
 	IIDAutoIncrement interface {
@@ -23,8 +49,20 @@ type (
 	HasPK[ID IID] interface {
 		GetPK() ID
 	}
+
+	// HasCompositePK is implemented by models with a primary key spanning multiple columns (e.g. join tables).
+	// GetCompositePK must always return the same columns in the same order, regardless of the model values.
+	HasCompositePK interface {
+		GetCompositePK() []PKColumn
+	}
 )
 
+// PKColumn is a single column of a composite primary key along with its value.
+type PKColumn struct {
+	Column string
+	Value  any
+}
+
 type PK[ID IID] struct {
 	ID ID `bun:"id,pk,notnull"`
 }
@@ -42,3 +80,145 @@ type PKUUID struct {
 }
 
 func (p *PKUUID) GetPK() uuid.UUID { return p.ID }
+
+// PKUUIDv7 is a time-ordered UUID primary key generated on insert if it's not set yet.
+// Generated IDs follow the insertion order, so the model could be iterated with the soft cursor of xquery.Select.
+//
+// IDs are generated by bun.BeforeInsertHook for all the inserted models at once, so it could be embedded together with
+// bun.BeforeAppendModelHook implementations (e.g. Timestamps). A model defining its own BeforeInsert must call the mixin's one.
+type PKUUIDv7 struct {
+	ID uuid.UUID `bun:"id,pk,type:uuid"`
+}
+
+func (p *PKUUIDv7) GetPK() uuid.UUID { return p.ID }
+
+func (p *PKUUIDv7) BeforeInsert(_ context.Context, query *bun.InsertQuery) error {
+	return generateIDs(query)
+}
+
+func (p *PKUUIDv7) generateID() error {
+	if p.ID != uuid.Nil {
+		return nil
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+
+	return nil
+}
+
+// ULID is ulid.ULID stored in its canonical 26-character text form, which is lexicographically sortable.
+// Unlike it, ulid.ULID itself is stored as 16 raw bytes (see ulid.ULID.Value).
+type ULID ulid.ULID
+
+// NewULID returns a new ULID with the current time.
+func NewULID() ULID { return ULID(ulid.Make()) }
+
+func (id ULID) String() string { return ulid.ULID(id).String() }
+
+func (id ULID) Value() (driver.Value, error) { return id.String(), nil }
+
+func (id *ULID) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*id = ULID{}
+		return nil
+	case string:
+		return (*ulid.ULID)(id).UnmarshalText([]byte(src))
+	case []byte:
+		return (*ulid.ULID)(id).UnmarshalText(src)
+	default:
+		return fmt.Errorf("unsupported ULID source type %T", src)
+	}
+}
+
+// PKULID is a time-ordered ULID primary key generated on insert if it's not set yet.
+// It's stored in its canonical 26-character text form (see ULID).
+// Generated IDs follow the insertion order, so the model could be iterated with the soft cursor of xquery.Select.
+//
+// See PKUUIDv7 regarding the ID generation hook.
+type PKULID struct {
+	ID ULID `bun:"id,pk,type:char(26)"`
+}
+
+func (p *PKULID) GetPK() ULID { return p.ID }
+
+func (p *PKULID) BeforeInsert(_ context.Context, query *bun.InsertQuery) error {
+	return generateIDs(query)
+}
+
+func (p *PKULID) generateID() error {
+	if p.ID == (ULID{}) {
+		p.ID = NewULID()
+	}
+
+	return nil
+}
+
+// PKSnowflake is a 64-bit time-ordered primary key generated by DefaultSnowflake on insert if it's not set yet.
+// Generated IDs are monotonic, so the model could be iterated with the soft cursor of xquery.Select just like PKAutoIncrement.
+//
+// See PKUUIDv7 regarding the ID generation hook.
+type PKSnowflake struct {
+	ID int64 `bun:"id,pk"`
+}
+
+func (p *PKSnowflake) GetPK() int64 { return p.ID }
+
+func (p *PKSnowflake) BeforeInsert(_ context.Context, query *bun.InsertQuery) error {
+	return generateIDs(query)
+}
+
+func (p *PKSnowflake) generateID() error {
+	if p.ID == 0 {
+		p.ID = DefaultSnowflake().Next()
+	}
+
+	return nil
+}
+
+// generateIDs generates the IDs of the inserted models implementing idGenerator.
+// It's shared by bun.BeforeInsertHook implementations of the primary key mixins, since the hook is called for the whole query
+// rather than for every model, unlike bun.BeforeAppendModelHook.
+func generateIDs(query *bun.InsertQuery) error {
+	model := query.GetModel()
+	if model == nil {
+		return nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(model.Value()))
+	if v.Kind() != reflect.Slice {
+		return generateID(v)
+	}
+
+	for i := range v.Len() {
+		if err := generateID(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateID(v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+	case v.CanAddr():
+		v = v.Addr()
+	default:
+		return nil
+	}
+
+	if g, ok := v.Interface().(idGenerator); ok {
+		return g.generateID()
+	}
+
+	return nil
+}
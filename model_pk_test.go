@@ -0,0 +1,162 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestPKUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+
+		var p1, p2 PKUUIDv7
+
+		require.NoError(t, p1.generateID())
+		require.NoError(t, p2.generateID())
+		require.Equal(t, uuid.Version(7), p1.ID.Version())
+		require.Less(t, p1.ID.String(), p2.ID.String())
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		t.Parallel()
+
+		id := uuid.New()
+		p := PKUUIDv7{ID: id}
+
+		require.NoError(t, p.generateID())
+		require.Equal(t, id, p.GetPK())
+	})
+}
+
+func TestPKULID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+
+		var p1, p2 PKULID
+
+		require.NoError(t, p1.generateID())
+		require.NoError(t, p2.generateID())
+		require.NotEqual(t, ULID{}, p1.ID)
+		require.Negative(t, ulid.ULID(p1.ID).Compare(ulid.ULID(p2.ID)))
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		t.Parallel()
+
+		id := NewULID()
+		p := PKULID{ID: id}
+
+		require.NoError(t, p.generateID())
+		require.Equal(t, id, p.GetPK())
+	})
+}
+
+func TestULID(t *testing.T) {
+	t.Parallel()
+
+	id := NewULID()
+
+	v, err := id.Value()
+	require.NoError(t, err)
+	require.Equal(t, id.String(), v)
+	require.Len(t, v, 26)
+
+	var scanned ULID
+
+	require.NoError(t, scanned.Scan(id.String()))
+	require.Equal(t, id, scanned)
+
+	require.NoError(t, scanned.Scan([]byte(id.String())))
+	require.Equal(t, id, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	require.Equal(t, ULID{}, scanned)
+
+	require.Error(t, scanned.Scan(42))
+	require.Error(t, scanned.Scan("invalid"))
+
+	type model struct {
+		bun.BaseModel `bun:"table:models"`
+		PKULID
+	}
+
+	q := testDB().NewInsert().Model(&model{PKULID: PKULID{ID: id}})
+	require.Equal(t, `INSERT INTO "models" ("id") VALUES ('`+id.String()+`')`, q.String())
+}
+
+func TestPKSnowflake(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+
+		var p1, p2 PKSnowflake
+
+		require.NoError(t, p1.generateID())
+		require.NoError(t, p2.generateID())
+		require.Positive(t, p1.GetPK())
+		require.Greater(t, p2.GetPK(), p1.GetPK())
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		t.Parallel()
+
+		p := PKSnowflake{ID: 42}
+
+		require.NoError(t, p.generateID())
+		require.EqualValues(t, 42, p.GetPK())
+	})
+}
+
+type testPKTimestampsModel struct {
+	bun.BaseModel `bun:"table:models,alias:m"`
+
+	PKUUIDv7
+	Timestamps
+}
+
+func TestPK_withTimestamps(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var queries []string
+
+	conn := captureConn{queries: &queries}
+
+	var m testPKTimestampsModel
+
+	_, err := testDB().NewInsert().Conn(conn).Model(&m).Exec(ctx)
+	require.ErrorIs(t, err, errCaptured)
+	require.NotEqual(t, uuid.Nil, m.GetPK())
+	require.False(t, m.CreatedAt.IsZero())
+	require.Equal(t, m.CreatedAt, m.UpdatedAt)
+	require.Contains(t, queries[0], m.GetPK().String())
+
+	ms := []*testPKTimestampsModel{{}, {}}
+
+	_, err = testDB().NewInsert().Conn(conn).Model(&ms).Exec(ctx)
+	require.ErrorIs(t, err, errCaptured)
+
+	for _, m := range ms {
+		require.NotEqual(t, uuid.Nil, m.GetPK())
+		require.False(t, m.CreatedAt.IsZero())
+	}
+
+	require.Less(t, ms[0].GetPK().String(), ms[1].GetPK().String())
+
+	values := make([]testPKTimestampsModel, 2)
+
+	_, err = testDB().NewInsert().Conn(conn).Model(&values).Exec(ctx)
+	require.ErrorIs(t, err, errCaptured)
+	require.NotEqual(t, uuid.Nil, values[1].GetPK())
+}
@@ -0,0 +1,111 @@
+package xbun
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+var _ Trackable = (*Tracking)(nil)
+
+// UnchangedPolicy defines what UpdateChanged does when no columns have changed since the last snapshot.
+type UnchangedPolicy uint8
+
+const (
+	// UnchangedTouch makes UpdateChanged fall back to UpdateColumns without columns, i.e. just touch the model.
+	UnchangedTouch UnchangedPolicy = iota
+
+	// UnchangedSkip makes UpdateChanged return no query at all.
+	UnchangedSkip
+)
+
+// Trackable is implemented by models embedding the Tracking mixin.
+type Trackable interface {
+	tracking() *Tracking
+}
+
+// Tracking is a mixin that stores the snapshot of model values for the change tracking.
+// It does not add any columns to the model.
+type Tracking struct {
+	snapshot map[string][]byte
+}
+
+func (t *Tracking) tracking() *Tracking { return t }
+
+// TakeSnapshot remembers the current column values of the given model, so UpdateChanged could detect changes made after that.
+// Usually it's called right after the model is loaded from the database and after every successful update.
+func TakeSnapshot(db bun.IDB, model Trackable) {
+	model.tracking().snapshot = snapshotValues(db, model)
+}
+
+// ChangedColumns returns the columns of the given model which values differ from the last snapshot.
+// If there is no snapshot taken, all the columns are considered changed.
+//
+// Primary key columns and the columns managed by Timestamps are never reported.
+// It returns nil if the model has no table, e.g. it's not a struct.
+func ChangedColumns(db bun.IDB, model Trackable) []string {
+	table := modelTable(db.Dialect(), model)
+	if table == nil {
+		return nil
+	}
+
+	snapshot := model.tracking().snapshot
+	current := snapshotValues(db, model)
+	changed := make([]string, 0, len(current))
+
+	for _, f := range table.DataFields {
+		value, ok := current[f.Name]
+		if !ok {
+			continue
+		}
+
+		if prev, ok := snapshot[f.Name]; ok && bytes.Equal(prev, value) {
+			continue
+		}
+
+		changed = append(changed, f.Name)
+	}
+
+	return changed
+}
+
+// UpdateChanged constructs an update statement affecting only the columns of the target model changed since the last snapshot.
+// See TakeSnapshot and ChangedColumns for details.
+//
+// If there are no changed columns, it either works just like UpdateColumns without columns or returns no query at all,
+// depending on the given UnchangedPolicy. The second return value reports whether the query is returned.
+func UpdateChanged(db bun.IDB, model Trackable, unchanged UnchangedPolicy) (*bun.UpdateQuery, bool) {
+	columns := ChangedColumns(db, model)
+	if len(columns) == 0 && unchanged == UnchangedSkip {
+		return nil, false
+	}
+
+	return UpdateColumns(db, model, columns...), true
+}
+
+// snapshotValues returns the SQL representation of every updatable data column value of the given model,
+// except the ones managed by Timestamps.
+func snapshotValues(db bun.IDB, model any) map[string][]byte {
+	table := modelTable(db.Dialect(), model)
+	if table == nil {
+		return nil
+	}
+
+	_, timestamped := model.(timestamped)
+
+	fmter := schema.NewFormatter(db.Dialect())
+	strct := reflect.Indirect(reflect.ValueOf(model))
+	values := make(map[string][]byte, len(table.DataFields))
+
+	for _, f := range table.DataFields {
+		if f.SkipUpdate() || (timestamped && (f.Name == createdAtColumn || f.Name == updatedAtColumn)) {
+			continue
+		}
+
+		values[f.Name] = f.AppendValue(fmter, nil, strct)
+	}
+
+	return values
+}
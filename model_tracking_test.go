@@ -0,0 +1,132 @@
+package xbun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type testTrackedModel struct {
+	bun.BaseModel `bun:"table:tracked,alias:t"`
+	PK[int64]
+	Timestamps
+	Tracking
+
+	Name string   `bun:"name"`
+	Tags []string `bun:"tags,array"`
+}
+
+// testTrackedNonStruct is Trackable without a table.
+type testTrackedNonStruct map[string]*Tracking
+
+func (m testTrackedNonStruct) tracking() *Tracking {
+	if m[""] == nil {
+		m[""] = &Tracking{}
+	}
+
+	return m[""]
+}
+
+func TestChangedColumns(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	t.Run("no snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{Name: "name"}
+		require.Equal(t, []string{"name", "tags"}, ChangedColumns(db, m))
+	})
+
+	t.Run("timestamps", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{Name: "name"}
+		TakeSnapshot(db, m)
+
+		m.CreatedAt = bun.NullTime{Time: time.Now()}
+		m.UpdatedAt = bun.NullTime{Time: time.Now()}
+		require.Empty(t, ChangedColumns(db, m))
+	})
+
+	t.Run("no table", func(t *testing.T) {
+		t.Parallel()
+
+		m := testTrackedNonStruct{}
+		TakeSnapshot(db, m)
+
+		require.Nil(t, ChangedColumns(db, m))
+
+		q, ok := UpdateChanged(db, m, UnchangedSkip)
+		require.False(t, ok)
+		require.Nil(t, q)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{Name: "name"}
+		TakeSnapshot(db, m)
+
+		m.UpdatedAt = bun.NullTime{}
+		require.Empty(t, ChangedColumns(db, m))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{Name: "name", Tags: []string{"a", "b"}}
+		TakeSnapshot(db, m)
+
+		m.Name = "new name"
+		m.Tags[1] = "c"
+		require.Equal(t, []string{"name", "tags"}, ChangedColumns(db, m))
+
+		TakeSnapshot(db, m)
+		require.Empty(t, ChangedColumns(db, m))
+	})
+}
+
+func TestUpdateChanged(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	t.Run("changed", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{PK: PK[int64]{ID: 1}, Name: "name"}
+		TakeSnapshot(db, m)
+
+		m.Name = "new name"
+
+		q, ok := UpdateChanged(db, m, UnchangedSkip)
+		require.True(t, ok)
+		require.Equal(t, `UPDATE "tracked" AS "t" SET "name" = 'new name' WHERE ("t"."id" = 1)`, q.String())
+	})
+
+	t.Run("unchanged touch", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{PK: PK[int64]{ID: 1}}
+		TakeSnapshot(db, m)
+
+		q, ok := UpdateChanged(db, m, UnchangedTouch)
+		require.True(t, ok)
+		require.Equal(t, UpdateColumns(db, m).String(), q.String())
+	})
+
+	t.Run("unchanged skip", func(t *testing.T) {
+		t.Parallel()
+
+		m := &testTrackedModel{PK: PK[int64]{ID: 1}}
+		TakeSnapshot(db, m)
+
+		q, ok := UpdateChanged(db, m, UnchangedSkip)
+		require.False(t, ok)
+		require.Nil(t, q)
+	})
+}
@@ -7,8 +7,23 @@ import (
 	"github.com/uptrace/bun"
 )
 
-var _ bun.BeforeAppendModelHook = (*Timestamps)(nil)
+var (
+	_ bun.BeforeAppendModelHook = (*Timestamps)(nil)
+	_ timestamped               = (*Timestamps)(nil)
+)
+
+const (
+	createdAtColumn = "created_at"
+	updatedAtColumn = "updated_at"
+)
 
+// timestamped is implemented by models embedding the Timestamps mixin.
+type timestamped interface {
+	timestamps() *Timestamps
+}
+
+// Timestamps sets the creation and update timestamps of the model.
+// A model defining its own BeforeAppendModel must call the mixin's one.
 type Timestamps struct {
 	CreatedAt bun.NullTime `bun:"created_at,nullzero,notnull"`
 	UpdatedAt bun.NullTime `bun:"updated_at,nullzero,notnull"`
@@ -25,10 +40,12 @@ func (t *Timestamps) BeforeAppendModel(_ context.Context, query bun.Query) error
 
 		t.UpdatedAt = now
 	case *bun.UpdateQuery:
-		q.Column("updated_at")
+		q.Column(updatedAtColumn)
 
 		t.UpdatedAt = now
 	}
 
 	return nil
 }
+
+func (t *Timestamps) timestamps() *Timestamps { return t }
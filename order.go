@@ -0,0 +1,140 @@
+package xbun
+
+import (
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+var _ schema.QueryAppender = OrderSpec{}
+
+// OrderSpec is a single ORDER BY item, which renders itself according to the query dialect.
+// It could be passed as an argument to bun's OrderExpr (e.g. `q.OrderExpr("?", spec)`) or applied with the Order option.
+type OrderSpec struct {
+	// Column is the column name qualified with the query's table alias.
+	Column string
+
+	// Expr is a raw SQL expression used instead of Column, e.g. `lower(?TableAlias.name)`.
+	// It's not escaped in any way, so it must never come from the user input.
+	Expr string
+
+	// Dir is the sorting direction. By default, it's OrderAsc.
+	Dir OrderDir
+
+	// Nulls is the placement of NULL values. By default, it's up to the database.
+	// MySQL and MSSQL lack `NULLS FIRST|LAST`, so it's emulated with the preceding `expr IS NULL` ordering there.
+	Nulls NullsOrder
+
+	// Collate is the optional collation name, e.g. `C` for PostgreSQL or `NOCASE` for SQLite.
+	Collate string
+}
+
+// Validate checks the spec fields.
+func (s OrderSpec) Validate() error {
+	if (s.Column == "") == (s.Expr == "") {
+		return errors.New("order: exactly one of column and expression is required")
+	}
+
+	switch s.Dir {
+	case "", OrderAsc, OrderDesc:
+	default:
+		return errors.New("order: invalid direction " + string(s.Dir))
+	}
+
+	switch s.Nulls {
+	case NullsDefault, NullsFirst, NullsLast:
+	default:
+		return errors.New("order: invalid nulls order " + string(s.Nulls))
+	}
+
+	return nil
+}
+
+// AppendQuery implements schema.QueryAppender.
+func (s OrderSpec) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	dir := s.Dir
+	if dir == "" {
+		dir = OrderAsc
+	}
+
+	name := fmter.Dialect().Name()
+	emulateNulls := s.Nulls != NullsDefault && (name == dialect.MySQL || name == dialect.MSSQL)
+
+	if emulateNulls {
+		if name == dialect.MSSQL {
+			b = append(b, "CASE WHEN "...)
+			b = s.appendExpr(fmter, b)
+			b = append(b, " IS NULL THEN 1 ELSE 0 END"...)
+		} else {
+			b = s.appendExpr(fmter, b)
+			b = append(b, " IS NULL"...)
+		}
+
+		if s.Nulls == NullsFirst {
+			b = append(b, " DESC"...)
+		} else {
+			b = append(b, " ASC"...)
+		}
+
+		b = append(b, ", "...)
+	}
+
+	b = s.appendExpr(fmter, b)
+
+	if s.Collate != "" {
+		b = fmter.AppendQuery(append(b, " COLLATE "...), "?", bun.Ident(s.Collate))
+	}
+
+	b = append(b, ' ')
+	b = append(b, dir...)
+
+	if s.Nulls != NullsDefault && !emulateNulls {
+		b = append(b, ' ')
+		b = append(b, s.Nulls...)
+	}
+
+	return b, nil
+}
+
+func (s OrderSpec) appendExpr(fmter schema.Formatter, b []byte) []byte {
+	if s.Expr != "" {
+		return fmter.AppendQuery(b, s.Expr)
+	}
+
+	return fmter.AppendQuery(b, "?TableAlias.?", bun.Ident(s.Column))
+}
+
+// EffectiveNulls returns the actual placement of NULL values with the given dialect,
+// which is the database default one if the spec doesn't specify it.
+func (s OrderSpec) EffectiveNulls(name dialect.Name) NullsOrder {
+	if s.Nulls != NullsDefault {
+		return s.Nulls
+	}
+
+	// PostgreSQL treats NULLs as larger than any value, MySQL, MSSQL and SQLite as smaller.
+	if (name == dialect.PG) == (s.Dir != OrderDesc) {
+		return NullsLast
+	}
+
+	return NullsFirst
+}
+
+// Order applies the given order specs to bun.SelectQuery.
+func Order(specs ...OrderSpec) QueryOption {
+	return untyped("Order", func(q *bun.SelectQuery) {
+		for _, spec := range specs {
+			if err := spec.Validate(); err != nil {
+				queryOptionErr(q, "Order", err.Error())
+				return
+			}
+
+			q.OrderExpr("?", spec)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/schema"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestOrderSpec(t *testing.T) {
+	t.Parallel()
+
+	specs := []OrderSpec{
+		{Column: "name", Dir: OrderDesc, Nulls: NullsLast, Collate: "C"},
+		{Expr: "lower(?TableAlias.name)", Nulls: NullsFirst},
+		{Column: "id"},
+	}
+
+	db := testDB()
+
+	q := QueryOptions(db.NewSelect().Model((*testModel)(nil)), Order(specs...))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" `+
+		`ORDER BY "m"."name" COLLATE "C" DESC NULLS LAST, lower("m".name) ASC NULLS FIRST, "m"."id" ASC`, q.String())
+
+	// MySQL dialect can't be used with bun.DB without the actual connection, so the formatter is used directly.
+	fmter := schema.NewFormatter(mysqldialect.New()).WithNamedArg("TableAlias", bun.Ident("m"))
+	want := []string{
+		"`m`.`name` IS NULL ASC, `m`.`name` COLLATE `C` DESC",
+		"lower(`m`.name) IS NULL DESC, lower(`m`.name) ASC",
+		"`m`.`id` ASC",
+	}
+
+	for i, spec := range specs {
+		b, err := spec.AppendQuery(fmter, nil)
+		require.NoError(t, err)
+		require.Equal(t, want[i], string(b))
+	}
+}
+
+func TestOrderSpec_EffectiveNulls(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, NullsLast, OrderSpec{}.EffectiveNulls(dialect.PG))
+	require.Equal(t, NullsFirst, OrderSpec{Dir: OrderDesc}.EffectiveNulls(dialect.PG))
+	require.Equal(t, NullsFirst, OrderSpec{Dir: OrderAsc}.EffectiveNulls(dialect.SQLite))
+	require.Equal(t, NullsLast, OrderSpec{Dir: OrderDesc}.EffectiveNulls(dialect.MySQL))
+	require.Equal(t, NullsFirst, OrderSpec{Nulls: NullsFirst}.EffectiveNulls(dialect.PG))
+}
+
+func TestOrder_error(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	for _, spec := range []OrderSpec{
+		{},
+		{Column: "id", Expr: "id"},
+		{Column: "id", Dir: "UP"},
+		{Column: "id", Nulls: "NULLS MIDDLE"},
+	} {
+		err := QueryOptions(db.NewSelect().Model((*testModel)(nil)), Order(spec)).Scan(ctx)
+		require.True(t, xerr.IsQueryOption(err))
+	}
+}
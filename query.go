@@ -1,20 +1,81 @@
 package xbun
 
 import (
+	"slices"
+
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
 )
 
 // UpdateColumns constructs an update statement affecting the given columns of the target model.
 // Passing no columns argument will result in no columns being updated, but bun.BeforeUpdateHook and bun.AfterUpdateHook being executed.
-// This is useful when you want to just touch the model (e.g. update timestamps).
+// This is useful when you want to just touch the model (e.g. update timestamps of the embedded Timestamps).
+// Models with composite primary keys are supported as well, as the primary key columns are taken from bun's table metadata.
 func UpdateColumns(db bun.IDB, model any, columns ...string) *bun.UpdateQuery {
 	q := db.NewUpdate().Model(model).WherePK()
 
 	if len(columns) == 0 { // just touch
-		q.Set("?TableAlias.id = ?TableAlias.id")
+		touch(db, q, model)
 	} else {
 		q.Column(columns...)
 	}
 
 	return q
 }
+
+// UpdateColumnsBulk works just like UpdateColumns, but for a slice of models updated with a single statement:
+//
+//	WITH _data (...) AS (VALUES ...) UPDATE t SET col = _data.col FROM _data WHERE t.id = _data.id
+//
+// The model must be a pointer to a slice of models with primary keys.
+// The number of affected rows matches the number of updated models, so the result could be checked with
+// ExpectResult(result, err, AffectedExactly(len(models))).
+func UpdateColumnsBulk(db bun.IDB, model any, columns ...string) *bun.UpdateQuery {
+	q := db.NewUpdate().Model(model)
+
+	if len(columns) == 0 { // just touch
+		if !isTimestamped(db, model) {
+			return touch(db, q.WherePK(), model)
+		}
+
+		columns = []string{updatedAtColumn}
+	} else if isTimestamped(db, model) && !slices.Contains(columns, updatedAtColumn) {
+		columns = append(columns[:len(columns):len(columns)], updatedAtColumn)
+	}
+
+	return q.Column(columns...).Bulk()
+}
+
+// touch makes the update query just touch the model.
+// If the model embeds Timestamps, the updated timestamp is being set, otherwise the first primary key column is set to itself.
+func touch(db bun.IDB, q *bun.UpdateQuery, model any) *bun.UpdateQuery {
+	if isTimestamped(db, model) {
+		return q.Set("? = ?"+updatedAtColumn, bun.Ident(updatedAtColumn))
+	}
+
+	pk := touchColumn(db, model)
+
+	return q.Set("? = ?TableAlias.?", pk, pk)
+}
+
+// touchColumn returns the column that could be set to itself to just touch the model.
+// It's the first primary key column of the model or `id` if there is no information about it.
+func touchColumn(db bun.IDB, model any) schema.Safe {
+	if table := modelTable(db.Dialect(), model); table != nil && len(table.PKs) > 0 {
+		return table.PKs[0].SQLName
+	}
+
+	return "id"
+}
+
+// isTimestamped reports whether the given model (or the slice element type) embeds Timestamps.
+func isTimestamped(db bun.IDB, model any) bool {
+	table := modelTable(db.Dialect(), model)
+	if table == nil {
+		return false
+	}
+
+	_, ok := table.ZeroIface.(timestamped)
+
+	return ok
+}
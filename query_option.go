@@ -1,12 +1,20 @@
 package xbun
 
 import (
+	"fmt"
+
 	"github.com/uptrace/bun"
 	"golang.org/x/exp/constraints"
+
+	"github.com/heffcodex/xbun/xerr"
 )
 
 // QueryOption is a function that modifies a query.
 // See functions below implementing these modifiers.
+//
+// If an option can't be applied to the given query (e.g. Limit applied to bun.UpdateQuery),
+// it records a xerr.QueryOptionError to the query, which is then returned by the query's Scan or Exec call.
+// Use TypedQueryOption for compile-time type safety instead.
 type QueryOption func(q bun.Query)
 
 // nopQueryOption is a QueryOption for internal use that does nothing.
@@ -14,7 +22,12 @@ func nopQueryOption(_ bun.Query) {}
 
 // QueryOptions sequentially applies the given query options to the given query.
 // The function accepts and returns query with the same type Q, so you don't need to cast it back from interface{} by yourself.
+// If the hook is set with SetSQLCommentHook, SQLComment is applied to the query as well.
 func QueryOptions[Q bun.Query](q Q, options ...QueryOption) Q {
+	if SQLCommentHook() != nil {
+		wrapQueryConn(q, func(c *queryConn) { c.comment(nil) })
+	}
+
 	for _, opt := range options {
 		opt(q)
 	}
@@ -22,30 +35,59 @@ func QueryOptions[Q bun.Query](q Q, options ...QueryOption) Q {
 	return q
 }
 
+// queryOptionErr records an error of the given option to the query, so it will be returned by the query's Scan or Exec call.
+// Every bun query type is able to hold an error, while custom bun.Query implementations are not,
+// so the error is dropped for them and the option is just not applied.
+func queryOptionErr(q bun.Query, option, reason string) {
+	err := xerr.ErrQueryOption(option, reason)
+
+	switch q := q.(type) {
+	case *bun.SelectQuery:
+		q.Err(err)
+	case *bun.InsertQuery:
+		q.Err(err)
+	case *bun.UpdateQuery:
+		q.Err(err)
+	case *bun.DeleteQuery:
+		q.Err(err)
+	case *bun.RawQuery:
+		q.Err(err)
+	case *bun.MergeQuery:
+		q.Err(err)
+	case *bun.ValuesQuery:
+		q.Err(err)
+	case *bun.CreateTableQuery:
+		q.Err(err)
+	case *bun.DropTableQuery:
+		q.Err(err)
+	case *bun.TruncateTableQuery:
+		q.Err(err)
+	case *bun.AddColumnQuery:
+		q.Err(err)
+	case *bun.DropColumnQuery:
+		q.Err(err)
+	case *bun.CreateIndexQuery:
+		q.Err(err)
+	case *bun.DropIndexQuery:
+		q.Err(err)
+	}
+}
+
+// unsupportedQuery records the error of applying the given option to the query of unsupported type.
+func unsupportedQuery(q bun.Query, option string) {
+	queryOptionErr(q, option, fmt.Sprintf("unsupported query type %T", q))
+}
+
 // Relations applies the given relations by their names (usually by a model struct related field) to bun.SelectQuery.
 func Relations(relations ...string) QueryOption {
-	return func(q bun.Query) {
-		selectQuery, ok := q.(*bun.SelectQuery)
-		if !ok {
-			panic("Relations only works with SelectQuery")
-		}
-
-		for _, relation := range relations {
-			selectQuery.Relation(relation)
-		}
-	}
+	return untyped("Relations", SelectRelations(relations...))
 }
 
 // SelectFor updates the bun.SelectQuery with the given FOR clause.
 func SelectFor(_for string) QueryOption {
-	return func(q bun.Query) {
-		selectQuery, ok := q.(*bun.SelectQuery)
-		if !ok {
-			panic("SelectFor only works with SelectQuery")
-		}
-
-		selectQuery.For(_for + " OF ?TableAlias")
-	}
+	return untyped("SelectFor", func(q *bun.SelectQuery) {
+		q.For(_for + " OF ?TableAlias")
+	})
 }
 
 // SelectForUpdate updates the bun.SelectQuery with the `FOR UPDATE` clause.
@@ -64,7 +106,7 @@ func WhereDeleted() QueryOption {
 		case *bun.DeleteQuery:
 			q.WhereDeleted()
 		default:
-			panic("WhereDeleted only works with SelectQuery, UpdateQuery, DeleteQuery")
+			unsupportedQuery(q, "WhereDeleted")
 		}
 	}
 }
@@ -81,7 +123,7 @@ func WhereAllWithDeleted() QueryOption {
 		case *bun.DeleteQuery:
 			q.WhereAllWithDeleted()
 		default:
-			panic("WhereAllWithDeleted only works with SelectQuery, UpdateQuery, DeleteQuery")
+			unsupportedQuery(q, "WhereAllWithDeleted")
 		}
 	}
 }
@@ -97,45 +139,28 @@ func WhereDeletedFlag(flag QueryFlag) QueryOption {
 	case QueryFlagOnly:
 		return WhereDeleted()
 	default:
-		panic("invalid flag") // this should never happen
+		return func(q bun.Query) {
+			queryOptionErr(q, "WhereDeletedFlag", fmt.Sprintf("invalid flag %d", flag))
+		}
 	}
 }
 
 // Offset sets the bun.SelectQuery's offset.
 func Offset[Int constraints.Integer](offset Int) QueryOption {
-	return func(q bun.Query) {
-		selectQuery, ok := q.(*bun.SelectQuery)
-		if !ok {
-			panic("Offset only works with SelectQuery")
-		}
-
-		selectQuery.Offset(int(offset))
-	}
+	return untyped("Offset", SelectOffset(offset))
 }
 
 // Limit sets the bun.SelectQuery's limit.
 func Limit[Int constraints.Integer](limit Int) QueryOption {
-	return func(q bun.Query) {
-		selectQuery, ok := q.(*bun.SelectQuery)
-		if !ok {
-			panic("Limit only works with SelectQuery")
-		}
-
-		selectQuery.Limit(int(limit))
-	}
+	return untyped("Limit", SelectLimit(limit))
 }
 
 // Paginate implements simple limit-offset-based pagination for bun.SelectQuery.
 func Paginate[Int constraints.Integer](page, per Int) QueryOption {
-	return func(q bun.Query) {
-		QueryOptions(q,
-			Offset((page-1)*per),
-			Limit(per),
-		)
-	}
+	return untyped("Paginate", SelectPaginate(page, per))
 }
 
-// Returning sets the given RETURNING clause for bun.SelectQuery, bun.UpdateQuery or bun.DeleteQuery.
+// Returning sets the given RETURNING clause for bun.InsertQuery, bun.UpdateQuery or bun.DeleteQuery.
 func Returning(ret string) QueryOption {
 	return func(q bun.Query) {
 		switch q := q.(type) {
@@ -146,12 +171,12 @@ func Returning(ret string) QueryOption {
 		case *bun.DeleteQuery:
 			q.Returning(ret)
 		default:
-			panic("Returning only works with InsertQuery, UpdateQuery, DeleteQuery")
+			unsupportedQuery(q, "Returning")
 		}
 	}
 }
 
-// ReturningAll sets the `RETURNING *` clause for bun.SelectQuery, bun.UpdateQuery or bun.DeleteQuery.
+// ReturningAll sets the `RETURNING *` clause for bun.InsertQuery, bun.UpdateQuery or bun.DeleteQuery.
 // Works just like Returning(RetAll).
 // Useful for update-by-id queries when you want to return the full updated model.
 func ReturningAll() QueryOption { return Returning(RetAll) }
@@ -0,0 +1,85 @@
+package xbun
+
+import (
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// LockStrength is the strength of the row-level lock acquired by SELECT.
+type LockStrength string
+
+const (
+	LockUpdate      LockStrength = "UPDATE"
+	LockNoKeyUpdate LockStrength = "NO KEY UPDATE"
+	LockShare       LockStrength = "SHARE"
+	LockKeyShare    LockStrength = "KEY SHARE"
+)
+
+// LockWait is the policy of waiting for the row-level lock acquired by SELECT.
+type LockWait string
+
+const (
+	// LockWaitDefault waits until the lock is released by other transactions.
+	LockWaitDefault LockWait = ""
+
+	// LockNoWait fails the query with xerr.LockNotAvailableError (when checked with ExpectSuccess or ExpectResult)
+	// if the lock can't be acquired immediately.
+	LockNoWait LockWait = "NOWAIT"
+
+	// LockSkipLocked skips the rows that can't be locked immediately.
+	LockSkipLocked LockWait = "SKIP LOCKED"
+)
+
+// Lock describes the locking clause of SELECT, e.g. `FOR NO KEY UPDATE OF t1, t2 SKIP LOCKED`.
+type Lock struct {
+	// Strength is the lock strength. By default, it's LockUpdate.
+	Strength LockStrength
+
+	// Wait is the lock waiting policy. By default, it's LockWaitDefault.
+	Wait LockWait
+
+	// Tables are the aliases of the tables to lock (e.g. joined ones). By default, it's the alias of the query model table.
+	Tables []string
+}
+
+// SelectLock applies the given locking clause to bun.SelectQuery.
+// Invalid strength or wait policy results in a xerr.QueryOptionError.
+func SelectLock(lock Lock) QueryOption {
+	return untyped("SelectLock", func(q *bun.SelectQuery) {
+		strength := lock.Strength
+
+		switch strength {
+		case "":
+			strength = LockUpdate
+		case LockUpdate, LockNoKeyUpdate, LockShare, LockKeyShare:
+		default:
+			queryOptionErr(q, "SelectLock", "invalid strength "+string(strength))
+			return
+		}
+
+		switch lock.Wait {
+		case LockWaitDefault, LockNoWait, LockSkipLocked:
+		default:
+			queryOptionErr(q, "SelectLock", "invalid wait policy "+string(lock.Wait))
+			return
+		}
+
+		clause := string(strength) + " OF ?TableAlias"
+		args := make([]any, len(lock.Tables))
+
+		if len(lock.Tables) > 0 {
+			for i, table := range lock.Tables {
+				args[i] = bun.Ident(table)
+			}
+
+			clause = string(strength) + " OF " + strings.Repeat("?, ", len(args)-1) + "?"
+		}
+
+		if lock.Wait != LockWaitDefault {
+			clause += " " + string(lock.Wait)
+		}
+
+		q.For(clause, args...)
+	})
+}
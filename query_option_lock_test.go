@@ -0,0 +1,47 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestSelectLock(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	tests := []struct {
+		name string
+		lock Lock
+		want string
+	}{
+		{"default", Lock{}, `SELECT "m"."name", "m"."id" FROM "models" AS "m" FOR UPDATE OF "m"`},
+		{"no key update nowait", Lock{Strength: LockNoKeyUpdate, Wait: LockNoWait}, `SELECT "m"."name", "m"."id" FROM "models" AS "m" FOR NO KEY UPDATE OF "m" NOWAIT`},
+		{"share skip locked", Lock{Strength: LockShare, Wait: LockSkipLocked}, `SELECT "m"."name", "m"."id" FROM "models" AS "m" FOR SHARE OF "m" SKIP LOCKED`},
+		{"key share tables", Lock{Strength: LockKeyShare, Tables: []string{"m", "j"}}, `SELECT "m"."name", "m"."id" FROM "models" AS "m" FOR KEY SHARE OF "m", "j"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, QueryOptions(db.NewSelect().Model((*testModel)(nil)), SelectLock(tt.lock)).String())
+		})
+	}
+}
+
+func TestSelectLock_invalid(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	err := QueryOptions(db.NewSelect().Model((*testModel)(nil)), SelectLock(Lock{Strength: "NOTHING"})).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	err = QueryOptions(db.NewSelect().Model((*testModel)(nil)), SelectLock(Lock{Wait: "FOREVER"})).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+}
@@ -0,0 +1,113 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestQueryOptions(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	q := QueryOptions(db.NewSelect().Model((*testModel)(nil)), Relations(), Paginate(3, 10), WhereDeletedFlag(QueryFlagNone))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" LIMIT 10 OFFSET 20`, q.String())
+}
+
+func TestQueryOptions_unsupported(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		query  bun.Query
+		option QueryOption
+	}{
+		{"Relations", db.NewUpdate().Model((*testModel)(nil)), Relations("Rel")},
+		{"SelectFor", db.NewDelete().Model((*testModel)(nil)), SelectForUpdate()},
+		{"Offset", db.NewInsert().Model(&testModel{}), Offset(1)},
+		{"Limit", db.NewUpdate().Model((*testModel)(nil)), Limit(1)},
+		{"Paginate", db.NewRaw("SELECT 1"), Paginate(1, 1)},
+		{"WhereDeleted", db.NewInsert().Model(&testModel{}), WhereDeleted()},
+		{"WhereAllWithDeleted", db.NewInsert().Model(&testModel{}), WhereAllWithDeleted()},
+		{"WhereDeletedFlag", db.NewSelect().Model((*testModel)(nil)), WhereDeletedFlag(QueryFlag(100))},
+		{"Where", db.NewInsert().Model(&testModel{}), Where("1 = 1")},
+		{"WhereOr", db.NewRaw("SELECT 1"), WhereOr("1 = 1")},
+		{"WhereGroup", db.NewInsert().Model(&testModel{}), WhereGroup(SepAND, Where("1 = 1"))},
+		{"WhereEq", db.NewInsert().Model(&testModel{}), WhereEq("id", 1)},
+		{"WhereIn", db.NewRaw("SELECT 1"), WhereIn("id", []int{})},
+		{"WhereILike", db.NewInsert().Model(&testModel{}), WhereILike("name", "a", LikeContains)},
+		{"WhereAny", db.NewInsert().Model(&testModel{}), WhereAny("id", []int{1})},
+		{"Returning", db.NewSelect().Model((*testModel)(nil)), ReturningAll()},
+		{"for *bun.SelectQuery", db.NewUpdate().Model((*testModel)(nil)), SelectLimit(1).Untyped()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+
+			switch q := QueryOptions(tt.query, tt.option).(type) {
+			case *bun.SelectQuery:
+				err = q.Scan(ctx)
+			case *bun.InsertQuery:
+				_, err = q.Exec(ctx)
+			case *bun.UpdateQuery:
+				_, err = q.Exec(ctx)
+			case *bun.DeleteQuery:
+				_, err = q.Exec(ctx)
+			case *bun.RawQuery:
+				_, err = q.Exec(ctx)
+			}
+
+			qoErr := xerr.QueryOptionError{}
+			require.ErrorAs(t, err, &qoErr)
+			require.Equal(t, tt.name, qoErr.Option())
+		})
+	}
+}
+
+// testCustomQuery is bun.Query implementation unable to hold an error.
+type testCustomQuery struct {
+	bun.Query
+}
+
+func TestQueryOptions_unsupportedOther(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, err := QueryOptions(testDB().NewCreateTable().Model((*testModel)(nil)), Limit(1)).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	_, err = QueryOptions(testDB().NewTruncateTable().Model((*testModel)(nil)), Where("1 = 1")).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	require.NotPanics(t, func() { QueryOptions(testCustomQuery{}, Limit(1), Where("1 = 1")) })
+}
+
+func TestTypedQueryOptions(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	q := TypedQueryOptions(db.NewSelect().Model((*testModel)(nil)), SelectRelations(), SelectPaginate(3, 10))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" LIMIT 10 OFFSET 20`, q.String())
+
+	iq := TypedQueryOptions(db.NewInsert().Model(&testModel{}), InsertReturning(RetAll))
+	require.Equal(t, `INSERT INTO "models" ("name", "id") VALUES ('', 0) RETURNING *`, iq.String())
+
+	uq := QueryOptions(UpdateColumns(db, &testModel{PK: PK[int64]{ID: 1}}, "name"), UpdateReturning(RetAll).Untyped())
+	require.Equal(t, `UPDATE "models" AS "m" SET "name" = '' WHERE ("m"."id" = 1) RETURNING *`, uq.String())
+
+	dq := TypedQueryOptions(db.NewDelete().Model(&testModel{PK: PK[int64]{ID: 1}}).WherePK(), DeleteReturning(RetAll))
+	require.Equal(t, `DELETE FROM "models" AS "m" WHERE ("m"."id" = 1) RETURNING *`, dq.String())
+}
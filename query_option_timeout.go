@@ -0,0 +1,80 @@
+package xbun
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// StatementTimeout bounds the server-side runtime of bun.SelectQuery, bun.InsertQuery, bun.UpdateQuery, bun.DeleteQuery or bun.RawQuery.
+// Exceeding it fails the query with xerr.StatementTimeoutError (when checked with ExpectSuccess or ExpectResult),
+// which is distinct from the context cancellation.
+//
+// With PostgreSQL, the query is prepended with `SET LOCAL statement_timeout = N;` and sent as a multi-statement query,
+// which requires the simple query protocol (github.com/uptrace/bun/driver/pgdriver and github.com/lib/pq, but not pgx),
+// so the query of any other driver results in a xerr.QueryOptionError.
+// When run with the database itself, both statements run in their own implicit transaction, so the timeout affects the query only.
+// Otherwise (e.g. within a transaction), the current timeout is saved before and restored after the query with additional statements,
+// so it doesn't affect the following queries of the transaction.
+//
+// With MySQL, the `MAX_EXECUTION_TIME` optimizer hint is used, which applies to bun.SelectQuery starting with SELECT only.
+// Other queries with MySQL (including the ones starting with WITH) and other dialects result in a xerr.QueryOptionError.
+func StatementTimeout(timeout time.Duration) QueryOption {
+	return func(q bun.Query) {
+		d, ok := q.(interface{ Dialect() schema.Dialect })
+		if !ok {
+			unsupportedQuery(q, "StatementTimeout")
+			return
+		}
+
+		name := d.Dialect().Name()
+
+		switch name {
+		case dialect.PG:
+			if !hasSimpleProtocolDriver(q) {
+				queryOptionErr(q, "StatementTimeout", "the simple query protocol driver (pgdriver or lib/pq) is required with "+name.String())
+				return
+			}
+		case dialect.MySQL:
+			if _, ok := q.(*bun.SelectQuery); !ok {
+				queryOptionErr(q, "StatementTimeout", "only SELECT queries are supported with "+name.String())
+				return
+			}
+		default:
+			queryOptionErr(q, "StatementTimeout", "unsupported dialect "+name.String())
+			return
+		}
+
+		if !wrapQueryConn(q, func(c *queryConn) { c.timeout, c.timeoutDialect = timeout, name }) {
+			unsupportedQuery(q, "StatementTimeout")
+		}
+	}
+}
+
+// simpleProtocolDrivers are the package paths of the PostgreSQL drivers supporting the multi-statement queries.
+var simpleProtocolDrivers = map[string]bool{
+	"github.com/uptrace/bun/driver/pgdriver": true,
+	"github.com/lib/pq":                      true,
+}
+
+// hasSimpleProtocolDriver reports whether the database of the query uses one of simpleProtocolDrivers.
+func hasSimpleProtocolDriver(q bun.Query) bool {
+	withDB, ok := q.(interface{ DB() *bun.DB })
+	if !ok || withDB.DB() == nil || withDB.DB().DB == nil {
+		return false
+	}
+
+	typ := reflect.TypeOf(withDB.DB().Driver())
+	if typ == nil {
+		return false
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return simpleProtocolDrivers[typ.PkgPath()]
+}
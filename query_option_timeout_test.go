@@ -0,0 +1,104 @@
+package xbun
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// testMySQLDB returns the database failing to connect, so the dialects probing the server version on init don't panic.
+func testMySQLDB() *bun.DB {
+	return bun.NewDB(sql.OpenDB(errConnector{err: errCaptured}), mysqldialect.New())
+}
+
+func TestStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	var queries []string
+
+	conn := captureConn{queries: &queries}
+
+	// The test database has no PostgreSQL driver, so the PostgreSQL timeout is set to the connection directly.
+	pgTimeout := func(timeout time.Duration) func(c *queryConn) {
+		return func(c *queryConn) { c.timeout, c.timeoutDialect = timeout, dialect.PG }
+	}
+
+	q := db.NewSelect().Conn(conn).Model((*testModel)(nil))
+	require.True(t, wrapQueryConn(q, pgTimeout(1500*time.Millisecond)))
+	require.ErrorIs(t, QueryOptions(q, SQLComment(map[string]string{"a": "1"})).Scan(ctx), errCaptured)
+
+	dq := db.NewDelete().Conn(conn).Model((*testModel)(nil)).Where("1 = 1")
+	require.True(t, wrapQueryConn(dq, pgTimeout(time.Microsecond)))
+	_, err := dq.Exec(ctx)
+	require.ErrorIs(t, err, errCaptured)
+
+	mq := QueryOptions(testMySQLDB().NewSelect().Conn(conn).Model((*testModel)(nil)), StatementTimeout(time.Second))
+	require.ErrorIs(t, mq.Scan(ctx), errCaptured)
+
+	// The captured connection is not *sql.DB, so the timeout is saved and restored.
+	require.Equal(t, []string{
+		pgSaveStatementTimeout + `; SET LOCAL statement_timeout = 1500; SELECT "m"."name", "m"."id" FROM "models" AS "m" /*a='1'*/; ` +
+			pgRestoreStatementTimeout,
+		pgSaveStatementTimeout + `; SET LOCAL statement_timeout = 1; DELETE FROM "models" AS "m" WHERE (1 = 1)`,
+		"SELECT /*+ MAX_EXECUTION_TIME(1000) */ `m`.`name`, `m`.`id` FROM `models` AS `m`",
+	}, queries)
+
+	// Queries run with *sql.DB have their own implicit transaction, so there is nothing to restore.
+	require.False(t, queryConn{conn: &sql.DB{}, timeout: time.Second, timeoutDialect: dialect.PG}.restoresTimeout())
+
+	query, err := applyStatementTimeout("SELECT 1", 10*time.Millisecond, dialect.PG, false)
+	require.NoError(t, err)
+	require.Equal(t, "SET LOCAL statement_timeout = 10; SELECT 1", query)
+}
+
+func TestStatementTimeout_unsupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, err := QueryOptions(
+		testMySQLDB().NewDelete().Model((*testModel)(nil)).Where("1 = 1"), StatementTimeout(time.Second),
+	).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	err = QueryOptions(bun.NewDB(&sql.DB{}, sqlitedialect.New()).NewSelect().Model((*testModel)(nil)), StatementTimeout(time.Second)).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	// The test database driver doesn't support the multi-statement queries.
+	err = QueryOptions(testDB().NewSelect().Model((*testModel)(nil)), StatementTimeout(time.Second)).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	// The hint can't be applied to the query starting with WITH.
+	var (
+		queries []string
+		n       int
+	)
+
+	err = QueryOptions(
+		testMySQLDB().NewSelect().Conn(captureConn{queries: &queries}).
+			With("t", testMySQLDB().NewSelect().ColumnExpr("1 AS n")).Table("t").Column("n"),
+		StatementTimeout(time.Second),
+	).Scan(ctx, &n)
+	require.True(t, xerr.IsQueryOption(err))
+
+	n, err = QueryOptions(
+		testMySQLDB().NewSelect().Conn(captureConn{queries: &queries}).
+			With("t", testMySQLDB().NewSelect().ColumnExpr("1 AS n")).Table("t"),
+		StatementTimeout(time.Second),
+	).Count(ctx)
+	require.Zero(t, n)
+	require.True(t, xerr.IsQueryOption(err))
+	require.Empty(t, queries)
+}
@@ -0,0 +1,94 @@
+package xbun
+
+import (
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"golang.org/x/exp/constraints"
+)
+
+// TypedQueryOption is a compile-time safe counterpart of QueryOption for the specific query type Q.
+type TypedQueryOption[Q bun.Query] func(q Q)
+
+// TypedQueryOptions sequentially applies the given typed query options to the given query.
+func TypedQueryOptions[Q bun.Query](q Q, options ...TypedQueryOption[Q]) Q {
+	for _, opt := range options {
+		opt(q)
+	}
+
+	return q
+}
+
+// Untyped converts the typed option to QueryOption, so it could be mixed with other options.
+// Applying the resulting option to a query of another type records a xerr.QueryOptionError to the query.
+func (o TypedQueryOption[Q]) Untyped() QueryOption {
+	var zero Q
+	return untyped(fmt.Sprintf("for %T", zero), o)
+}
+
+// untyped converts the typed option to QueryOption with the given option name used for errors.
+func untyped[Q bun.Query](name string, o TypedQueryOption[Q]) QueryOption {
+	return func(q bun.Query) {
+		typed, ok := q.(Q)
+		if !ok {
+			unsupportedQuery(q, name)
+			return
+		}
+
+		o(typed)
+	}
+}
+
+// SelectRelations is a typed counterpart of Relations.
+func SelectRelations(relations ...string) TypedQueryOption[*bun.SelectQuery] {
+	return func(q *bun.SelectQuery) {
+		for _, relation := range relations {
+			q.Relation(relation)
+		}
+	}
+}
+
+// SelectOffset is a typed counterpart of Offset.
+func SelectOffset[Int constraints.Integer](offset Int) TypedQueryOption[*bun.SelectQuery] {
+	return func(q *bun.SelectQuery) {
+		q.Offset(int(offset))
+	}
+}
+
+// SelectLimit is a typed counterpart of Limit.
+func SelectLimit[Int constraints.Integer](limit Int) TypedQueryOption[*bun.SelectQuery] {
+	return func(q *bun.SelectQuery) {
+		q.Limit(int(limit))
+	}
+}
+
+// SelectPaginate is a typed counterpart of Paginate.
+func SelectPaginate[Int constraints.Integer](page, per Int) TypedQueryOption[*bun.SelectQuery] {
+	return func(q *bun.SelectQuery) {
+		TypedQueryOptions(q,
+			SelectOffset((page-1)*per),
+			SelectLimit(per),
+		)
+	}
+}
+
+// InsertReturning is a typed counterpart of Returning for bun.InsertQuery.
+func InsertReturning(ret string) TypedQueryOption[*bun.InsertQuery] {
+	return func(q *bun.InsertQuery) {
+		q.Returning(ret)
+	}
+}
+
+// UpdateReturning is a typed counterpart of Returning for bun.UpdateQuery.
+func UpdateReturning(ret string) TypedQueryOption[*bun.UpdateQuery] {
+	return func(q *bun.UpdateQuery) {
+		q.Returning(ret)
+	}
+}
+
+// DeleteReturning is a typed counterpart of Returning for bun.DeleteQuery.
+func DeleteReturning(ret string) TypedQueryOption[*bun.DeleteQuery] {
+	return func(q *bun.DeleteQuery) {
+		q.Returning(ret)
+	}
+}
@@ -0,0 +1,131 @@
+package xbun
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// ConflictTarget is the conflict target of the `ON CONFLICT` clause.
+// Use ConflictColumns or OnConstraint to construct it, the zero value means no target (allowed for OnConflictDoNothing only).
+type ConflictTarget struct {
+	query string
+	args  []any
+}
+
+// ConflictColumns returns the conflict target inferring a unique index on the given columns, e.g. `(a, b)`.
+func ConflictColumns(columns ...string) ConflictTarget {
+	if len(columns) == 0 {
+		return ConflictTarget{}
+	}
+
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		args[i] = bun.Ident(column)
+	}
+
+	return ConflictTarget{
+		query: "(" + strings.Repeat("?, ", len(columns)-1) + "?)",
+		args:  args,
+	}
+}
+
+// OnConstraint returns the conflict target naming the constraint explicitly, i.e. `ON CONSTRAINT name`.
+func OnConstraint(name string) ConflictTarget {
+	return ConflictTarget{
+		query: "ON CONSTRAINT ?",
+		args:  []any{bun.Ident(name)},
+	}
+}
+
+// on applies the `ON CONFLICT [target] action` clause to the query.
+func (t ConflictTarget) on(q *bun.InsertQuery, action string) {
+	if t.query == "" {
+		q.On("CONFLICT " + action)
+		return
+	}
+
+	q.On("CONFLICT "+t.query+" "+action, t.args...)
+}
+
+// OnConflictDoNothing adds the `ON CONFLICT [target] DO NOTHING` clause to bun.InsertQuery.
+// Rows skipped due to the conflict are not counted as affected, so the number of actually inserted rows could be checked with ExpectResult.
+func OnConflictDoNothing(target ConflictTarget) QueryOption {
+	return untyped("OnConflictDoNothing", func(q *bun.InsertQuery) {
+		target.on(q, "DO NOTHING")
+	})
+}
+
+// OnConflictUpdate adds the `ON CONFLICT target DO UPDATE SET col = EXCLUDED.col, ...` clause for the given columns to bun.InsertQuery.
+// Both inserted and updated rows are counted as affected by ExpectResult, see Upsert to tell them apart.
+// The target is required, since `DO UPDATE` is not allowed without it.
+func OnConflictUpdate(target ConflictTarget, columns ...string) QueryOption {
+	return untyped("OnConflictUpdate", func(q *bun.InsertQuery) {
+		if target.query == "" {
+			queryOptionErr(q, "OnConflictUpdate", "no conflict target")
+			return
+		}
+
+		if len(columns) == 0 {
+			queryOptionErr(q, "OnConflictUpdate", "no columns to update")
+			return
+		}
+
+		setExcluded(q, target, columns)
+	})
+}
+
+// OnConflictUpdateAllExcept works just like OnConflictUpdate, but updates all the model columns except the given ones.
+// Primary key columns are never updated, as well as the creation timestamp of the models embedding Timestamps.
+func OnConflictUpdateAllExcept(target ConflictTarget, excluded ...string) QueryOption {
+	return untyped("OnConflictUpdateAllExcept", func(q *bun.InsertQuery) {
+		if target.query == "" {
+			queryOptionErr(q, "OnConflictUpdateAllExcept", "no conflict target")
+			return
+		}
+
+		table := queryTable(q)
+		if table == nil {
+			queryOptionErr(q, "OnConflictUpdateAllExcept", "no table model")
+			return
+		}
+
+		if _, ok := table.ZeroIface.(timestamped); ok {
+			excluded = append(excluded[:len(excluded):len(excluded)], createdAtColumn)
+		}
+
+		columns := make([]string, 0, len(table.DataFields))
+
+		for _, f := range table.DataFields {
+			if !f.SkipUpdate() && !slices.Contains(excluded, f.Name) {
+				columns = append(columns, f.Name)
+			}
+		}
+
+		if len(columns) == 0 {
+			queryOptionErr(q, "OnConflictUpdateAllExcept", "no columns to update")
+			return
+		}
+
+		setExcluded(q, target, columns)
+	})
+}
+
+// OnConflictWhere adds the condition to the `ON CONFLICT ... DO UPDATE` clause of bun.InsertQuery,
+// so only the conflicting rows matching it are updated (e.g. `?TableAlias.version < EXCLUDED.version`).
+// Could be applied multiple times, the conditions are joined with AND.
+func OnConflictWhere(query string, args ...any) QueryOption {
+	return untyped("OnConflictWhere", func(q *bun.InsertQuery) {
+		q.Where(query, args...)
+	})
+}
+
+// setExcluded applies the `ON CONFLICT target DO UPDATE SET col = EXCLUDED.col, ...` clause to the query.
+func setExcluded(q *bun.InsertQuery, target ConflictTarget, columns []string) {
+	target.on(q, "DO UPDATE")
+
+	for _, column := range columns {
+		q.Set("? = EXCLUDED.?", bun.Ident(column), bun.Ident(column))
+	}
+}
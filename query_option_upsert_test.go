@@ -0,0 +1,82 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestOnConflict(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	tests := []struct {
+		name    string
+		model   any
+		options []QueryOption
+		want    string
+	}{
+		{
+			"do nothing", &testModel{}, []QueryOption{OnConflictDoNothing(ConflictTarget{})},
+			`INSERT INTO "models" AS "m" ("name", "id") VALUES ('', 0) ON CONFLICT DO NOTHING`,
+		},
+		{
+			"do nothing columns", &testModel{}, []QueryOption{OnConflictDoNothing(ConflictColumns("name"))},
+			`INSERT INTO "models" AS "m" ("name", "id") VALUES ('', 0) ON CONFLICT ("name") DO NOTHING`,
+		},
+		{
+			"do nothing constraint", &testModel{}, []QueryOption{OnConflictDoNothing(OnConstraint("models_name_key"))},
+			`INSERT INTO "models" AS "m" ("name", "id") VALUES ('', 0) ON CONFLICT ON CONSTRAINT "models_name_key" DO NOTHING`,
+		},
+		{
+			"update", &testTimestampedModel{}, []QueryOption{
+				OnConflictUpdate(ConflictColumns("id"), "name"),
+				OnConflictWhere("?TableAlias.name <> EXCLUDED.name"),
+			},
+			`INSERT INTO "timestamped" AS "t" ("name", "id", "created_at", "updated_at") VALUES ('', 0, DEFAULT, DEFAULT) ` +
+				`ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" WHERE ("t".name <> EXCLUDED.name) ` +
+				`RETURNING "created_at", "updated_at"`,
+		},
+		{
+			"update all except", &testTimestampedModel{}, []QueryOption{
+				OnConflictUpdateAllExcept(OnConstraint("timestamped_pkey"), "name"),
+			},
+			`INSERT INTO "timestamped" AS "t" ("name", "id", "created_at", "updated_at") VALUES ('', 0, DEFAULT, DEFAULT) ` +
+				`ON CONFLICT ON CONSTRAINT "timestamped_pkey" DO UPDATE SET "updated_at" = EXCLUDED."updated_at" ` +
+				`RETURNING "created_at", "updated_at"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, QueryOptions(db.NewInsert().Model(tt.model), tt.options...).String())
+		})
+	}
+}
+
+func TestOnConflict_errors(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	_, err := QueryOptions(db.NewInsert().Model(&testModel{}), OnConflictUpdate(ConflictColumns("id"))).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	_, err = QueryOptions(db.NewInsert().Model(&testJoinModel{}), OnConflictUpdateAllExcept(OnConstraint("join_pkey"))).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	_, err = QueryOptions(db.NewInsert().Model(&testModel{}), OnConflictUpdate(ConflictTarget{}, "name")).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	_, err = QueryOptions(db.NewInsert().Model(&testModel{}), OnConflictUpdateAllExcept(ConflictTarget{})).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	_, err = QueryOptions(db.NewUpdate().Model(&testModel{}), OnConflictDoNothing(ConflictTarget{})).Exec(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+}
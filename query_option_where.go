@@ -0,0 +1,218 @@
+package xbun
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// LikeMatch defines how WhereILike matches the value.
+type LikeMatch uint8
+
+const (
+	LikeContains LikeMatch = iota
+	LikePrefix
+	LikeSuffix
+	LikeExact
+	LikePattern // the value is the LIKE pattern itself, i.e. its wildcards are not escaped
+)
+
+// likeEscape is the escape character of LIKE patterns built by WhereILike.
+const likeEscape = `\`
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// Where adds the WHERE condition joined with AND to bun.SelectQuery, bun.UpdateQuery or bun.DeleteQuery.
+func Where(query string, args ...any) QueryOption {
+	return func(q bun.Query) {
+		applyWhere(q, "Where", whereCond{query: query, args: args})
+	}
+}
+
+// WhereOr works just like Where, but joins the condition with OR.
+func WhereOr(query string, args ...any) QueryOption {
+	return func(q bun.Query) {
+		applyWhere(q, "WhereOr", whereCond{sep: SepOR, query: query, args: args})
+	}
+}
+
+// WhereGroup applies the given options (usually Where and WhereOr ones) within the parenthesized group joined with the given separator,
+// which is one of SepAND and SepOR optionally followed by SepNOT (e.g. `" AND NOT "`).
+// Note that bun omits the separator of the very first condition of the query or the enclosing group, including its NOT.
+// Available for bun.SelectQuery, bun.UpdateQuery and bun.DeleteQuery.
+func WhereGroup(sep string, options ...QueryOption) QueryOption {
+	return func(q bun.Query) {
+		applyWhere(q, "WhereGroup", whereCond{sep: sep, group: options, isGroup: true})
+	}
+}
+
+// The following options add the typical column conditions joined with AND.
+// Column names are quoted as identifiers and qualified with the query's table alias unless they are qualified already (e.g. `u.status`).
+// As Where, they are available for bun.SelectQuery, bun.UpdateQuery and bun.DeleteQuery.
+
+// WhereEq adds the `column = value` condition.
+func WhereEq(column string, value any) QueryOption {
+	return whereColumn("WhereEq", column, " = ?", value)
+}
+
+// WhereNotEq adds the `column <> value` condition.
+func WhereNotEq(column string, value any) QueryOption {
+	return whereColumn("WhereNotEq", column, " <> ?", value)
+}
+
+// WhereIn adds the `column IN (values...)` condition.
+// An empty slice results in the always false condition rather than the invalid `IN ()` SQL.
+func WhereIn[T any](column string, values []T) QueryOption {
+	if len(values) == 0 {
+		return func(q bun.Query) {
+			applyWhere(q, "WhereIn", whereCond{query: "1 = 0"})
+		}
+	}
+
+	return whereColumn("WhereIn", column, " IN (?)", bun.In(values))
+}
+
+// WhereBetween adds the `column BETWEEN from AND to` condition.
+func WhereBetween(column string, from, to any) QueryOption {
+	return whereColumn("WhereBetween", column, " BETWEEN ? AND ?", from, to)
+}
+
+// WhereILike adds the case-insensitive LIKE condition matching the value according to match.
+// The value is matched literally, i.e. `%` and `_` wildcards within it are escaped, unless match is LikePattern.
+// ILIKE is used for PostgreSQL, `LOWER(column) LIKE LOWER(pattern)` for other dialects.
+func WhereILike(column, value string, match LikeMatch) QueryOption {
+	expr, args := columnExpr(column)
+	return whereILike("WhereILike", expr, args, value, match)
+}
+
+// WhereILikeExpr works just like WhereILike, but matches the SQL expression with its args instead of the column.
+func WhereILikeExpr(expr string, exprArgs []any, value string, match LikeMatch) QueryOption {
+	return whereILike("WhereILikeExpr", expr, exprArgs, value, match)
+}
+
+func whereILike(option, expr string, exprArgs []any, value string, match LikeMatch) QueryOption {
+	pattern := value
+	if match != LikePattern {
+		pattern = likeEscaper.Replace(value)
+	}
+
+	switch match {
+	case LikeContains:
+		pattern = "%" + pattern + "%"
+	case LikePrefix:
+		pattern += "%"
+	case LikeSuffix:
+		pattern = "%" + pattern
+	case LikeExact, LikePattern:
+	}
+
+	return func(q bun.Query) {
+		args := append(append([]any(nil), exprArgs...), pattern, likeEscape)
+
+		if d, ok := q.(tableQuery); ok && d.Dialect().Name() == dialect.PG {
+			applyWhere(q, option, whereCond{query: expr + " ILIKE ? ESCAPE ?", args: args})
+			return
+		}
+
+		applyWhere(q, option, whereCond{query: "LOWER(" + expr + ") LIKE LOWER(?) ESCAPE ?", args: args})
+	}
+}
+
+// WhereIsNull adds the `column IS NULL` condition.
+func WhereIsNull(column string) QueryOption {
+	return whereColumn("WhereIsNull", column, " IS NULL")
+}
+
+// WhereIsNotNull adds the `column IS NOT NULL` condition.
+func WhereIsNotNull(column string) QueryOption {
+	return whereColumn("WhereIsNotNull", column, " IS NOT NULL")
+}
+
+// WhereAny adds the PostgreSQL-only `column = ANY(array)` condition.
+// Unlike WhereIn, the values are passed as a single array, so the query text doesn't depend on their number.
+func WhereAny[T any](column string, values []T) QueryOption {
+	return func(q bun.Query) {
+		if d, ok := q.(tableQuery); !ok || d.Dialect().Name() != dialect.PG {
+			queryOptionErr(q, "WhereAny", "unsupported dialect")
+			return
+		}
+
+		whereColumn("WhereAny", column, " = ANY(?)", &pgArray[T]{Values: values})(q)
+	}
+}
+
+// pgArray is the PostgreSQL array of the values. It's appended by the array appender of the query's dialect,
+// i.e. the one of the fields tagged with `array`, so the dialect package is not needed.
+type pgArray[T any] struct {
+	Values []T `bun:"values,array"`
+}
+
+func (a *pgArray[T]) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	table := fmter.Dialect().Tables().Get(reflect.TypeOf(a))
+	return table.FieldMap["values"].AppendValue(fmter, b, reflect.ValueOf(a).Elem()), nil
+}
+
+// whereColumn returns the option adding the condition on the column followed by the given SQL.
+func whereColumn(option, column, sql string, args ...any) QueryOption {
+	return func(q bun.Query) {
+		expr, exprArgs := columnExpr(column)
+		applyWhere(q, option, whereCond{query: expr + sql, args: append(exprArgs, args...)})
+	}
+}
+
+// columnExpr returns the quoted column expression and its args, qualified with the query's table alias if necessary.
+func columnExpr(column string) (string, []any) {
+	if strings.Contains(column, ".") {
+		return "?", []any{bun.Ident(column)}
+	}
+
+	return "?TableAlias.?", []any{bun.Ident(column)}
+}
+
+// whereQuery is the WHERE API shared by bun.SelectQuery, bun.UpdateQuery and bun.DeleteQuery.
+type whereQuery[Q any] interface {
+	bun.Query
+	Where(query string, args ...any) Q
+	WhereOr(query string, args ...any) Q
+	WhereGroup(sep string, fn func(Q) Q) Q
+}
+
+// whereCond is a single WHERE condition or, if isGroup is set, a parenthesized group of the conditions added by the options.
+type whereCond struct {
+	sep   string // SepOR joins the condition with OR, anything else with AND; for groups, it's passed to bun as is
+	query string
+	args  []any
+
+	isGroup bool
+	group   []QueryOption
+}
+
+// applyWhere adds the WHERE condition to the query, recording an error of the given option if it's not supported.
+func applyWhere(q bun.Query, option string, cond whereCond) {
+	switch q := q.(type) {
+	case *bun.SelectQuery:
+		addWhere(q, cond)
+	case *bun.UpdateQuery:
+		addWhere(q, cond)
+	case *bun.DeleteQuery:
+		addWhere(q, cond)
+	default:
+		unsupportedQuery(q, option)
+	}
+}
+
+// addWhere adds the WHERE condition to the query of the known type.
+func addWhere[Q whereQuery[Q]](q Q, cond whereCond) {
+	switch {
+	case cond.isGroup:
+		q.WhereGroup(cond.sep, func(q Q) Q { return QueryOptions(q, cond.group...) })
+	case cond.sep == SepOR:
+		q.WhereOr(cond.query, cond.args...)
+	default:
+		q.Where(cond.query, cond.args...)
+	}
+}
@@ -0,0 +1,84 @@
+package xbun
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestWhereGroup(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	q := QueryOptions(db.NewSelect().Model((*testModel)(nil)),
+		Where("?TableAlias.name = ?", "a"),
+		WhereGroup(SepOR,
+			Where("?TableAlias.id > ?", 1),
+			WhereOr("?TableAlias.id < ?", -1),
+		),
+		WhereGroup(SepAND+"NOT ", Where("?TableAlias.id = ?", 0)),
+	)
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" `+
+		`WHERE ("m".name = 'a') OR (("m".id > 1) OR ("m".id < -1)) AND NOT (("m".id = 0))`, q.String())
+
+	uq := QueryOptions(db.NewUpdate().Model((*testModel)(nil)).Set("name = ?", "b"),
+		Where("?TableAlias.id = ?", 1),
+		WhereOr("?TableAlias.id = ?", 2),
+	)
+	require.Equal(t, `UPDATE "models" AS "m" SET name = 'b' WHERE ("m".id = 1) OR ("m".id = 2)`, uq.String())
+
+	dq := QueryOptions(db.NewDelete().Model((*testModel)(nil)), WhereGroup(SepAND, Where("?TableAlias.id = ?", 1)))
+	require.Equal(t, `DELETE FROM "models" AS "m" WHERE (("m".id = 1))`, dq.String())
+}
+
+func TestWhereColumn(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	tests := []struct {
+		name   string
+		option QueryOption
+		want   string
+	}{
+		{"eq", WhereEq("name", "a"), `"m"."name" = 'a'`},
+		{"eq qualified", WhereEq("x.name", "a"), `"x"."name" = 'a'`},
+		{"eq injection", WhereEq(`name" = '' OR "1`, 1), `"m"."name"" = '' OR ""1" = 1`},
+		{"not eq", WhereNotEq("name", "a"), `"m"."name" <> 'a'`},
+		{"in", WhereIn("id", []int64{1, 2}), `"m"."id" IN (1, 2)`},
+		{"in empty", WhereIn("id", []int64{}), `1 = 0`},
+		{"between", WhereBetween("id", 1, 10), `"m"."id" BETWEEN 1 AND 10`},
+		{"ilike contains", WhereILike("name", `50%_off\`, LikeContains), `"m"."name" ILIKE '%50\%\_off\\%' ESCAPE '\'`},
+		{"ilike prefix", WhereILike("name", "a", LikePrefix), `"m"."name" ILIKE 'a%' ESCAPE '\'`},
+		{"ilike suffix", WhereILike("name", "a", LikeSuffix), `"m"."name" ILIKE '%a' ESCAPE '\'`},
+		{"ilike exact", WhereILike("name", "a", LikeExact), `"m"."name" ILIKE 'a' ESCAPE '\'`},
+		{"ilike pattern", WhereILike("name", "a%", LikePattern), `"m"."name" ILIKE 'a%' ESCAPE '\'`},
+		{"ilike expr", WhereILikeExpr("? || ''", []any{bun.Ident("name")}, "a", LikePrefix), `"name" || '' ILIKE 'a%' ESCAPE '\'`},
+		{"is null", WhereIsNull("name"), `"m"."name" IS NULL`},
+		{"is not null", WhereIsNotNull("name"), `"m"."name" IS NOT NULL`},
+		{"any", WhereAny("id", []int64{1, 2}), `"m"."id" = ANY('{1,2}')`},
+		{"any strings", WhereAny("name", []string{"a", `b"'`}), `"m"."name" = ANY('{"a","b\"''"}')`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := QueryOptions(db.NewSelect().Model((*testModel)(nil)), tt.option)
+			require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" WHERE (`+tt.want+`)`, q.String())
+		})
+	}
+}
+
+func TestWhereILike_notPG(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, sqlitedialect.New())
+
+	q := QueryOptions(db.NewSelect().Model((*testModel)(nil)), WhereILike("name", "a_", LikePrefix))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" WHERE (LOWER("m"."name") LIKE LOWER('a\_%') ESCAPE '\')`, q.String())
+}
@@ -0,0 +1,110 @@
+package xbun
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+type testModel struct {
+	bun.BaseModel `bun:"table:models,alias:m"`
+	PK[int64]
+
+	Name string `bun:"name"`
+}
+
+type testJoinModel struct {
+	bun.BaseModel `bun:"table:memberships,alias:ms"`
+
+	UserID  int64 `bun:"user_id,pk"`
+	GroupID int64 `bun:"group_id,pk"`
+}
+
+type testTimestampedModel struct {
+	bun.BaseModel `bun:"table:timestamped,alias:t"`
+	PK[int64]
+	Timestamps
+
+	Name string `bun:"name"`
+}
+
+var testTime = bun.NullTime{Time: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)}
+
+func testDB() *bun.DB {
+	return bun.NewDB(sql.OpenDB(errConnector{err: errCaptured}), pgdialect.New())
+}
+
+func TestUpdateColumns(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	tests := []struct {
+		name    string
+		model   any
+		columns []string
+		want    string
+	}{
+		{"columns", &testModel{PK: PK[int64]{ID: 1}}, []string{"name"}, `UPDATE "models" AS "m" SET "name" = '' WHERE ("m"."id" = 1)`},
+		{"touch", &testModel{PK: PK[int64]{ID: 1}}, nil, `UPDATE "models" AS "m" SET "id" = "m"."id" WHERE ("m"."id" = 1)`},
+		{
+			"touch timestamps", &testTimestampedModel{PK: PK[int64]{ID: 1}, Timestamps: Timestamps{UpdatedAt: testTime}}, nil,
+			`UPDATE "timestamped" AS "t" SET "updated_at" = '2024-01-02 03:04:05+00:00' WHERE ("t"."id" = 1)`,
+		},
+		{
+			"touch composite", &testJoinModel{UserID: 1, GroupID: 2}, nil,
+			`UPDATE "memberships" AS "ms" SET "user_id" = "ms"."user_id" WHERE ("ms"."user_id" = 1 AND "ms"."group_id" = 2)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, UpdateColumns(db, tt.model, tt.columns...).String())
+		})
+	}
+}
+
+func TestUpdateColumnsBulk(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	tests := []struct {
+		name    string
+		model   any
+		columns []string
+		want    string
+	}{
+		{
+			"columns", &[]*testModel{{PK: PK[int64]{ID: 1}, Name: "a"}, {PK: PK[int64]{ID: 2}, Name: "b"}}, []string{"name"},
+			`WITH "_data" ("name", "id") AS (VALUES ('a'::VARCHAR, 1::BIGINT), ('b'::VARCHAR, 2::BIGINT)) ` +
+				`UPDATE "models" AS "m" SET "name" = _data."name" FROM _data WHERE ("m"."id" = _data."id")`,
+		},
+		{
+			"touch", &[]*testModel{{PK: PK[int64]{ID: 1}}, {PK: PK[int64]{ID: 2}}}, nil,
+			`UPDATE "models" AS "m" SET "id" = "m"."id" WHERE "m"."id" IN (1, 2)`,
+		},
+		{
+			"columns timestamps", &[]*testTimestampedModel{{PK: PK[int64]{ID: 1}, Name: "a"}}, []string{"name"},
+			`WITH "_data" ("name", "id", "created_at", "updated_at") AS (VALUES ('a'::VARCHAR, 1::BIGINT, NULL::TIMESTAMPTZ, NULL::TIMESTAMPTZ)) ` +
+				`UPDATE "timestamped" AS "t" SET "name" = _data."name", "updated_at" = _data."updated_at" FROM _data WHERE ("t"."id" = _data."id")`,
+		},
+		{
+			"touch timestamps", &[]*testTimestampedModel{{PK: PK[int64]{ID: 1}}}, nil,
+			`WITH "_data" ("name", "id", "created_at", "updated_at") AS (VALUES (''::VARCHAR, 1::BIGINT, NULL::TIMESTAMPTZ, NULL::TIMESTAMPTZ)) ` +
+				`UPDATE "timestamped" AS "t" SET "updated_at" = _data."updated_at" FROM _data WHERE ("t"."id" = _data."id")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, UpdateColumnsBulk(db, tt.model, tt.columns...).String())
+		})
+	}
+}
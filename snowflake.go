@@ -0,0 +1,118 @@
+package xbun
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snowflake ID layout: 41 bits of milliseconds since the epoch, 10 bits of worker ID and 12 bits of sequence number.
+const (
+	SnowflakeWorkerBits   = 10
+	SnowflakeSequenceBits = 12
+
+	SnowflakeMaxWorkerID = 1<<SnowflakeWorkerBits - 1
+	SnowflakeMaxSequence = 1<<SnowflakeSequenceBits - 1
+)
+
+// DefaultSnowflakeEpoch is the epoch used by Snowflake if none is configured.
+var DefaultSnowflakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var defaultSnowflake atomic.Pointer[Snowflake]
+
+func init() {
+	defaultSnowflake.Store(&Snowflake{epoch: DefaultSnowflakeEpoch.UnixMilli()})
+}
+
+// DefaultSnowflake returns the generator used by PKSnowflake.
+// Initially, it uses DefaultSnowflakeEpoch and zero worker ID.
+func DefaultSnowflake() *Snowflake {
+	return defaultSnowflake.Load()
+}
+
+// SetDefaultSnowflake replaces the generator used by PKSnowflake.
+// Every process sharing the same database table must use its own worker ID, otherwise the uniqueness is not guaranteed.
+func SetDefaultSnowflake(s *Snowflake) {
+	defaultSnowflake.Store(s)
+}
+
+// SnowflakeWorkerIDFunc is a source of the worker ID for Snowflake (e.g. from the environment or a pod ordinal).
+type SnowflakeWorkerIDFunc func() (int64, error)
+
+// SnowflakeConfig configures NewSnowflake.
+type SnowflakeConfig struct {
+	// Epoch is the time the ID timestamps are counted from. By default, it's DefaultSnowflakeEpoch.
+	Epoch time.Time
+
+	// WorkerID returns the worker ID in range [0, SnowflakeMaxWorkerID]. By default, it's zero.
+	WorkerID SnowflakeWorkerIDFunc
+}
+
+// Snowflake is a generator of 64-bit time-ordered IDs, which are unique across workers without any coordination.
+// It's safe for concurrent use.
+type Snowflake struct {
+	epoch    int64
+	workerID int64
+
+	mu       sync.Mutex
+	lastMS   int64
+	sequence int64
+}
+
+// NewSnowflake creates a new Snowflake generator with the given configuration.
+func NewSnowflake(cfg SnowflakeConfig) (*Snowflake, error) {
+	s := &Snowflake{epoch: DefaultSnowflakeEpoch.UnixMilli()}
+
+	if !cfg.Epoch.IsZero() {
+		s.epoch = cfg.Epoch.UnixMilli()
+	}
+
+	if cfg.WorkerID != nil {
+		workerID, err := cfg.WorkerID()
+		if err != nil {
+			return nil, err
+		}
+
+		if workerID < 0 || workerID > SnowflakeMaxWorkerID {
+			return nil, errors.New("snowflake worker ID out of range: " + strconv.FormatInt(workerID, 10))
+		}
+
+		s.workerID = workerID
+	}
+
+	return s, nil
+}
+
+// Next returns the next ID.
+// IDs generated by the same generator are strictly increasing.
+// When the clock goes backwards or the sequence overflows, the timestamp part borrows from the future to keep this guarantee.
+func (s *Snowflake) Next() int64 {
+	ms := time.Now().UnixMilli() - s.epoch
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case ms > s.lastMS:
+		s.lastMS = ms
+		s.sequence = 0
+	case s.sequence < SnowflakeMaxSequence:
+		s.sequence++
+	default:
+		s.lastMS++
+		s.sequence = 0
+	}
+
+	return s.lastMS<<(SnowflakeWorkerBits+SnowflakeSequenceBits) | s.workerID<<SnowflakeSequenceBits | s.sequence
+}
+
+// Parse decomposes the given ID back to its generation time, worker ID and sequence number.
+func (s *Snowflake) Parse(id int64) (ts time.Time, workerID, sequence int64) {
+	ts = time.UnixMilli(id>>(SnowflakeWorkerBits+SnowflakeSequenceBits) + s.epoch).UTC()
+	workerID = id >> SnowflakeSequenceBits & SnowflakeMaxWorkerID
+	sequence = id & SnowflakeMaxSequence
+
+	return ts, workerID, sequence
+}
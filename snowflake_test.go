@@ -0,0 +1,107 @@
+package xbun
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSnowflake(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := NewSnowflake(SnowflakeConfig{})
+		require.NoError(t, err)
+
+		ts, workerID, _ := s.Parse(s.Next())
+		require.WithinDuration(t, time.Now(), ts, time.Second)
+		require.Zero(t, workerID)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+
+		epoch := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		s, err := NewSnowflake(SnowflakeConfig{
+			Epoch:    epoch,
+			WorkerID: func() (int64, error) { return SnowflakeMaxWorkerID, nil },
+		})
+		require.NoError(t, err)
+
+		ts, workerID, _ := s.Parse(s.Next())
+		require.WithinDuration(t, time.Now(), ts, time.Second)
+		require.EqualValues(t, SnowflakeMaxWorkerID, workerID)
+	})
+
+	t.Run("invalid worker ID", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewSnowflake(SnowflakeConfig{WorkerID: func() (int64, error) { return SnowflakeMaxWorkerID + 1, nil }})
+		require.Error(t, err)
+
+		_, err = NewSnowflake(SnowflakeConfig{WorkerID: func() (int64, error) { return -1, nil }})
+		require.Error(t, err)
+	})
+
+	t.Run("worker ID error", func(t *testing.T) {
+		t.Parallel()
+
+		wantErr := errors.New("no worker ID")
+
+		_, err := NewSnowflake(SnowflakeConfig{WorkerID: func() (int64, error) { return 0, wantErr }})
+		require.ErrorIs(t, err, wantErr)
+	})
+}
+
+func TestSnowflake_Next(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers   = 8
+		perWorker = 10000
+	)
+
+	s, err := NewSnowflake(SnowflakeConfig{})
+	require.NoError(t, err)
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[int64]struct{}, workers*perWorker)
+		wg   sync.WaitGroup
+	)
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ids := make([]int64, perWorker)
+			for i := range ids {
+				ids[i] = s.Next()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for i, id := range ids {
+				if i > 0 {
+					assert.Greater(t, id, ids[i-1])
+				}
+
+				seen[id] = struct{}{}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Len(t, seen, workers*perWorker)
+}
@@ -0,0 +1,150 @@
+package xbun
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// Sort modifiers accepted by ParseSort after the field name.
+const (
+	sortNullsFirst = ":nulls_first"
+	sortNullsLast  = ":nulls_last"
+)
+
+// SortField is a single field of Sort.
+type SortField struct {
+	// Name is the public name of the field.
+	Name string
+
+	// Expr is the column expression the field is mapped to, e.g. `?TableAlias.created_at`.
+	Expr string
+
+	Dir   OrderDir
+	Nulls NullsOrder
+}
+
+// Sort is a validated multi-column sort order.
+type Sort []SortField
+
+// ParseSort parses the sort order from the string like `-created_at,name:nulls_last`.
+// Fields are comma-separated; a leading `-` means OrderDesc (`+` or nothing means OrderAsc);
+// an optional `:nulls_first` or `:nulls_last` suffix sets the NULLs placement.
+//
+// Fields are validated against the allowed map of public names to the column expressions.
+// The expressions are not escaped in any way, so they must never come from the user input;
+// an empty expression means the column of the same name as the field, qualified with the query's table alias.
+//
+// Invalid input results in a xerr.SortError. An empty string results in an empty Sort.
+func ParseSort(s string, allowed map[string]string) (Sort, error) {
+	if strings.TrimSpace(s) == "" {
+		return Sort{}, nil
+	}
+
+	items := strings.Split(s, ",")
+	sort := make(Sort, 0, len(items))
+
+	for _, item := range items {
+		f, err := parseSortField(strings.TrimSpace(item), allowed)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(sort, func(sf SortField) bool { return sf.Name == f.Name }) {
+			return nil, xerr.ErrSort(f.Name, "duplicate field")
+		}
+
+		sort = append(sort, f)
+	}
+
+	return sort, nil
+}
+
+func parseSortField(item string, allowed map[string]string) (SortField, error) {
+	f := SortField{Dir: OrderAsc}
+
+	switch {
+	case strings.HasPrefix(item, "-"):
+		f.Dir, item = OrderDesc, item[1:]
+	case strings.HasPrefix(item, "+"):
+		item = item[1:]
+	}
+
+	switch {
+	case strings.HasSuffix(item, sortNullsFirst):
+		f.Nulls, item = NullsFirst, strings.TrimSuffix(item, sortNullsFirst)
+	case strings.HasSuffix(item, sortNullsLast):
+		f.Nulls, item = NullsLast, strings.TrimSuffix(item, sortNullsLast)
+	}
+
+	if item == "" {
+		return SortField{}, xerr.ErrSort("", "empty field")
+	}
+
+	if name, modifier, ok := strings.Cut(item, ":"); ok {
+		return SortField{}, xerr.ErrSort(name, "unknown modifier "+modifier)
+	}
+
+	expr, ok := allowed[item]
+	if !ok {
+		return SortField{}, xerr.ErrSort(item, "unknown field")
+	}
+
+	f.Name = item
+	f.Expr = expr
+
+	return f, nil
+}
+
+// SortBy applies the given sort order to bun.SelectQuery followed by the ascending primary key columns of the model
+// not sorted by explicitly yet, so the order is always stable (e.g. for pagination).
+func SortBy(sort Sort) QueryOption {
+	return untyped("SortBy", func(q *bun.SelectQuery) {
+		table := queryTable(q)
+		if table == nil || len(table.PKs) == 0 {
+			queryOptionErr(q, "SortBy", "no table model with primary key")
+			return
+		}
+
+		for _, f := range sort {
+			spec := f.OrderSpec()
+			if err := spec.Validate(); err != nil {
+				queryOptionErr(q, "SortBy", err.Error())
+				return
+			}
+
+			q.OrderExpr("?", spec)
+		}
+
+		for _, pk := range table.PKs {
+			if !slices.ContainsFunc(sort, func(f SortField) bool { return f.column() == pk.Name }) {
+				q.OrderExpr("?", OrderSpec{Column: pk.Name, Dir: OrderAsc})
+			}
+		}
+	})
+}
+
+// column returns the column name of the field if it's sorted by the model column rather than an arbitrary expression.
+func (f SortField) column() string {
+	switch f.Expr {
+	case "":
+		return f.Name
+	case "?TableAlias." + f.Name:
+		return f.Name
+	default:
+		return ""
+	}
+}
+
+// OrderSpec returns the order spec of the field.
+func (f SortField) OrderSpec() OrderSpec {
+	spec := OrderSpec{Expr: f.Expr, Dir: f.Dir, Nulls: f.Nulls}
+	if spec.Expr == "" {
+		spec.Column = f.Name
+	}
+
+	return spec
+}
@@ -0,0 +1,96 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+var testSortAllowed = map[string]string{
+	"name":    "",
+	"id":      "?TableAlias.id",
+	"name_ci": "lower(?TableAlias.name)",
+}
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+
+	sort, err := ParseSort(" -name_ci:nulls_last, +id ,name:nulls_first", testSortAllowed)
+	require.NoError(t, err)
+	require.Equal(t, Sort{
+		{Name: "name_ci", Expr: "lower(?TableAlias.name)", Dir: OrderDesc, Nulls: NullsLast},
+		{Name: "id", Expr: "?TableAlias.id", Dir: OrderAsc},
+		{Name: "name", Dir: OrderAsc, Nulls: NullsFirst},
+	}, sort)
+
+	sort, err = ParseSort("", testSortAllowed)
+	require.NoError(t, err)
+	require.Empty(t, sort)
+}
+
+func TestParseSort_error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sort  string
+		field string
+	}{
+		{"unknown", "unknown"},
+		{"name,", ""},
+		{"-", ""},
+		{"name:nulls_middle", "name"},
+		{"name,-name", "name"},
+		{"name; DROP TABLE models", "name; DROP TABLE models"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseSort(tt.sort, testSortAllowed)
+
+			sErr := xerr.SortError{}
+			require.ErrorAs(t, err, &sErr)
+			require.Equal(t, tt.field, sErr.Field())
+		})
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	sort, err := ParseSort("-name_ci:nulls_last,name", testSortAllowed)
+	require.NoError(t, err)
+
+	q := QueryOptions(db.NewSelect().Model((*testModel)(nil)), SortBy(sort))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" `+
+		`ORDER BY lower("m".name) DESC NULLS LAST, "m"."name" ASC, "m"."id" ASC`, q.String())
+
+	sort, err = ParseSort("-id", testSortAllowed)
+	require.NoError(t, err)
+
+	q = QueryOptions(db.NewSelect().Model((*testModel)(nil)), SortBy(sort))
+	require.Equal(t, `SELECT "m"."name", "m"."id" FROM "models" AS "m" ORDER BY "m".id DESC`, q.String())
+
+	q = QueryOptions(db.NewSelect().Model((*testJoinModel)(nil)), SortBy(Sort{}))
+	require.Equal(t, `SELECT "ms"."user_id", "ms"."group_id" FROM "memberships" AS "ms" `+
+		`ORDER BY "ms"."user_id" ASC, "ms"."group_id" ASC`, q.String())
+}
+
+func TestSortBy_error(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+	ctx := context.Background()
+
+	err := QueryOptions(db.NewSelect().Model((*testModel)(nil)), SortBy(Sort{{Name: "name", Dir: "SIDEWAYS"}})).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+
+	err = QueryOptions(db.NewSelect().TableExpr("models"), SortBy(Sort{})).Scan(ctx)
+	require.True(t, xerr.IsQueryOption(err))
+}
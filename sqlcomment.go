@@ -0,0 +1,100 @@
+package xbun
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/uptrace/bun"
+)
+
+// SQLCommentHookFunc returns the sqlcommenter tags known globally for the query execution context.
+type SQLCommentHookFunc func(ctx context.Context) map[string]string
+
+var sqlCommentHook atomic.Pointer[SQLCommentHookFunc]
+
+// SQLCommentHook returns the hook set with SetSQLCommentHook, or nil if there is none.
+func SQLCommentHook() SQLCommentHookFunc {
+	if fn := sqlCommentHook.Load(); fn != nil {
+		return *fn
+	}
+
+	return nil
+}
+
+// SetSQLCommentHook sets the hook, which makes QueryOptions apply SQLComment to every query it's called for
+// (xquery selectors included), and which result is added to the tags of every commented query.
+// It's the place for the tags known globally, e.g. the application name or the trace context (traceparent) of the current span.
+// A nil hook unsets it.
+func SetSQLCommentHook(fn SQLCommentHookFunc) {
+	if fn == nil {
+		sqlCommentHook.Store(nil)
+		return
+	}
+
+	sqlCommentHook.Store(&fn)
+}
+
+// sqlCommentContextKey is the context key of the sqlcommenter tags.
+type sqlCommentContextKey struct{}
+
+// ContextWithSQLComment returns a copy of the context carrying the sqlcommenter tags (e.g. route) merged with the ones it already carries.
+// The tags are added to the queries commented with SQLComment and executed with the context.
+func ContextWithSQLComment(ctx context.Context, tags map[string]string) context.Context {
+	merged := maps.Clone(SQLCommentFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+
+	maps.Copy(merged, tags)
+
+	return context.WithValue(ctx, sqlCommentContextKey{}, merged)
+}
+
+// SQLCommentFromContext returns the sqlcommenter tags carried by the context. The result must not be modified.
+func SQLCommentFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(sqlCommentContextKey{}).(map[string]string)
+	return tags
+}
+
+// SQLComment appends the sqlcommenter comment, e.g. `/*app='x',route='y'*/`, to bun.SelectQuery, bun.InsertQuery, bun.UpdateQuery,
+// bun.DeleteQuery or bun.RawQuery when it's executed.
+// The comment tags are the ones of the hook set with SetSQLCommentHook, overridden by the ones of the execution context (see ContextWithSQLComment),
+// overridden by the given ones. No comment is appended if there are no tags.
+//
+// The comment is appended by the connection wrapper set to the query (see queryConn),
+// so it's not visible to bun.QueryHook's and the query must not have its connection changed afterward.
+func SQLComment(tags map[string]string) QueryOption {
+	return func(q bun.Query) {
+		if !wrapQueryConn(q, func(c *queryConn) { c.comment(tags) }) {
+			unsupportedQuery(q, "SQLComment")
+		}
+	}
+}
+
+// FormatSQLComment returns the sqlcommenter comment with the given tags sorted by key,
+// or an empty string if there are no tags.
+// Keys and values are URL-encoded, so they can't break out of the comment.
+func FormatSQLComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+
+	for i, k := range keys {
+		pairs[i] = url.PathEscape(k) + "='" + url.PathEscape(tags[k]) + "'"
+	}
+
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
@@ -0,0 +1,89 @@
+package xbun
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+var errCaptured = errors.New("captured")
+
+// captureConn is bun.IConn capturing the executed queries instead of running them.
+type captureConn struct {
+	queries *[]string
+}
+
+func (c captureConn) QueryContext(_ context.Context, query string, _ ...any) (*sql.Rows, error) {
+	*c.queries = append(*c.queries, query)
+	return nil, errCaptured
+}
+
+func (c captureConn) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return nil, errCaptured
+}
+
+func (c captureConn) QueryRowContext(_ context.Context, query string, _ ...any) *sql.Row {
+	*c.queries = append(*c.queries, query)
+	return nil
+}
+
+func TestFormatSQLComment(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, FormatSQLComment(nil))
+	require.Equal(t,
+		`/*app='svc',route='%2Fusers%2F%7Bid%7D',x%20y='it%27s%20%2A%2F'*/`,
+		FormatSQLComment(map[string]string{"route": "/users/{id}", "app": "svc", "x y": "it's */"}),
+	)
+}
+
+func TestSQLComment(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	var queries []string
+
+	conn := captureConn{queries: &queries}
+	ctx := ContextWithSQLComment(context.Background(), map[string]string{"route": "/a", "app": "ctx"})
+	ctx = ContextWithSQLComment(ctx, map[string]string{"route": "/b"})
+
+	q := QueryOptions(db.NewSelect().Conn(conn).Model((*testModel)(nil)), SQLComment(map[string]string{"app": "svc"}), Limit(1))
+	require.ErrorIs(t, q.Scan(ctx), errCaptured)
+
+	uq := QueryOptions(db.NewUpdate().Conn(conn).Model(&testModel{}).WherePK(), SQLComment(map[string]string{"a": "1"}), SQLComment(nil))
+	_, err := uq.Exec(context.Background())
+	require.ErrorIs(t, err, errCaptured)
+
+	rq := QueryOptions(db.NewRaw("SELECT 1").Conn(conn), SQLComment(nil))
+	_, err = rq.Exec(context.Background())
+	require.ErrorIs(t, err, errCaptured)
+
+	require.Equal(t, []string{
+		`SELECT "m"."name", "m"."id" FROM "models" AS "m" LIMIT 1 /*app='svc',route='%2Fb'*/`,
+		`UPDATE "models" AS "m" SET "name" = '' WHERE ("m"."id" = 0) /*a='1'*/`,
+		`SELECT 1`,
+	}, queries)
+}
+
+// TestSQLCommentHook is not parallel, since it sets the global hook.
+func TestSQLCommentHook(t *testing.T) {
+	SetSQLCommentHook(func(context.Context) map[string]string { return map[string]string{"app": "svc"} })
+	t.Cleanup(func() { SetSQLCommentHook(nil) })
+
+	var queries []string
+
+	q := QueryOptions(testDB().NewDelete().Conn(captureConn{queries: &queries}).Model((*testModel)(nil)).Where("1 = 1"))
+	_, err := q.Exec(ContextWithSQLComment(context.Background(), map[string]string{"route": "/a"}))
+	require.ErrorIs(t, err, errCaptured)
+
+	require.Equal(t, []string{`DELETE FROM "models" AS "m" WHERE (1 = 1) /*app='svc',route='%2Fa'*/`}, queries)
+
+	// Query types unable to hold the connection are left as is.
+	require.NotPanics(t, func() { QueryOptions[bun.Query](testDB().NewCreateTable().Model((*testModel)(nil))) })
+}
@@ -0,0 +1,75 @@
+// Package sqlnorm normalizes the formatted SQL queries, so the same queries executed with different arguments are equal.
+package sqlnorm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Normalize replaces string and numeric literals of the query with `?`, collapses the lists of them,
+// e.g. `IN (?, ?, ?)`, into a single `?` and squeezes the whitespace.
+// Quoted identifiers are kept as is.
+func Normalize(query string) string {
+	var b strings.Builder
+
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i, '\'')
+			b.WriteByte('?')
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c)
+			b.WriteString(query[i:end])
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentByte(query[i-1])):
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+
+			b.WriteByte('?')
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < len(query) && (query[i] == ' ' || query[i] == '\t' || query[i] == '\n' || query[i] == '\r') {
+				i++
+			}
+
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return placeholderListRe.ReplaceAllString(strings.TrimSpace(b.String()), "?")
+}
+
+var placeholderListRe = regexp.MustCompile(`\?(?:\s*,\s*\?)+`)
+
+// skipQuoted returns the index after the quoted token starting at i, treating doubled quotes as escaped ones.
+func skipQuoted(s string, i int, quote byte) int {
+	for i++; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,29 @@
+package sqlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT 1`, `SELECT ?`},
+		{`SELECT "t1"."id" FROM "t1" WHERE ("t1"."id" > 10)  LIMIT 2`, `SELECT "t1"."id" FROM "t1" WHERE ("t1"."id" > ?) LIMIT ?`},
+		{`SELECT * FROM t WHERE name = 'it''s' AND x IN (1, 2.5, 'a')`, `SELECT * FROM t WHERE name = ? AND x IN (?)`},
+		{"SELECT `a1`\n\tFROM t2", "SELECT `a1` FROM t2"},
+		{`SELECT $1, "q""1"`, `SELECT $1, "q""1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Normalize(tt.query))
+		})
+	}
+}
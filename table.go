@@ -0,0 +1,44 @@
+package xbun
+
+import (
+	"reflect"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// tableQuery is implemented by bun's queries having a model.
+type tableQuery interface {
+	GetModel() bun.Model
+	Dialect() schema.Dialect
+}
+
+// queryTable returns bun's table metadata for the model of the given query, or nil if the query has no table model.
+func queryTable(q tableQuery) *schema.Table {
+	model := q.GetModel()
+	if model == nil {
+		return nil
+	}
+
+	return modelTable(q.Dialect(), model.Value())
+}
+
+// modelTable returns bun's table metadata for the given model.
+// The model could be a struct, a slice of structs or any (possibly multilevel) pointer to them.
+// Returns nil if the model type is not a struct.
+func modelTable(dialect schema.Dialect, model any) *schema.Table {
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return nil
+	}
+
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return dialect.Tables().Get(typ)
+}
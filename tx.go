@@ -0,0 +1,96 @@
+package xbun
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/uptrace/bun"
+)
+
+// txContextKey is the context key of the ambient transaction.
+type txContextKey struct{}
+
+// txDetachedContextKey is the context key of the transaction set with ContextWithDetachedTx.
+type txDetachedContextKey struct{}
+
+// ContextWithTx returns a copy of the context carrying the given transaction as the ambient one.
+func ContextWithTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// ContextWithDetachedTx works just like ContextWithTx, but also makes the outermost savepoints of the given transaction
+// started with RunInTx act as the outermost transactions regarding OnCommit and OnRollback hooks,
+// i.e. the hooks are run once such savepoint is released or rolled back.
+// It's meant for the transactions which outcome doesn't matter, e.g. the test ones, which are always rolled back.
+func ContextWithDetachedTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ContextWithTx(ctx, tx), txDetachedContextKey{}, tx)
+}
+
+// TxFromContext returns the ambient transaction of the context, if any.
+func TxFromContext(ctx context.Context) (bun.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(bun.Tx)
+	return tx, ok
+}
+
+// TxOrDB returns the database handle the queries should be run with:
+// the given db itself if it's a transaction already, the ambient transaction of the context if any, or the given db otherwise.
+func TxOrDB(ctx context.Context, db bun.IDB) bun.IDB {
+	if _, ok := db.(bun.Tx); ok {
+		return db
+	}
+
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// RunInTx runs the function within a transaction, which is committed if the function returns nil and rolled back otherwise.
+// If there is a transaction already (either the given db or the ambient one, see TxOrDB), a savepoint of it is used instead,
+// so the nested calls are released with `RELEASE SAVEPOINT` or rolled back with `ROLLBACK TO SAVEPOINT` without affecting the outer transaction.
+// Transaction options are ignored for savepoints.
+//
+// The transaction (or savepoint) is stored in the context passed to the function as the ambient one,
+// so the nested calls of RunInTx and other helpers (e.g. xquery.Select) join it even if they are given the original db.
+// The context also allows to register OnCommit and OnRollback hooks, which are run once the outermost RunInTx call resolves.
+// Hooks are not available if the outermost transaction is not started with RunInTx
+// (e.g. bun.Tx is given directly or set with ContextWithTx), since its outcome is unknown then:
+// OnCommit and OnRollback return an error instead of running the hooks at the savepoint release
+// (see ContextWithDetachedTx for the opposite).
+func RunInTx(ctx context.Context, db bun.IDB, opts *sql.TxOptions, fn func(ctx context.Context, tx bun.Tx) error) error {
+	idb := TxOrDB(ctx, db)
+
+	var parent *txHooks
+	if _, nested := idb.(bun.Tx); nested {
+		if parent = txHooksFromContext(ctx); parent == nil && !isDetachedTx(ctx, idb) {
+			return idb.RunInTx(ctx, opts, func(ctx context.Context, tx bun.Tx) error {
+				return fn(ContextWithTx(ctx, tx), tx)
+			})
+		}
+	}
+
+	hooks := &txHooks{}
+
+	// The transaction is rolled back by bun if fn panics, so the hooks are resolved as failed before the panic goes on.
+	defer func() {
+		if r := recover(); r != nil {
+			hooks.resolve(ctx, parent, false)
+			panic(r)
+		}
+	}()
+
+	err := idb.RunInTx(ctx, opts, func(ctx context.Context, tx bun.Tx) error {
+		return fn(contextWithTxHooks(ContextWithTx(ctx, tx), hooks), tx)
+	})
+
+	hooks.resolve(ctx, parent, err == nil)
+
+	return err
+}
+
+// isDetachedTx reports whether the given transaction is set with ContextWithDetachedTx.
+func isDetachedTx(ctx context.Context, idb bun.IDB) bool {
+	tx, ok := ctx.Value(txDetachedContextKey{}).(bun.Tx)
+	return ok && idb == tx
+}
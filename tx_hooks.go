@@ -0,0 +1,140 @@
+package xbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// TxHookFunc is a transaction hook callback registered with OnCommit or OnRollback.
+type TxHookFunc func(ctx context.Context) error
+
+// TxHookErrorHandler is called for every error returned (or panic raised) by a transaction hook.
+// Hook failures never affect the transaction outcome, since it's already resolved when hooks are run.
+// By default, errors are logged with slog.Default().
+var TxHookErrorHandler = func(ctx context.Context, err error) {
+	slog.ErrorContext(ctx, "xbun: transaction hook failed", slog.Any("error", err))
+}
+
+// errNoTxHooks is returned by OnCommit and OnRollback called outside of RunInTx.
+var errNoTxHooks = errors.New("no transaction started with RunInTx in context")
+
+// txHooksContextKey is the context key of the transaction hooks scope.
+type txHooksContextKey struct{}
+
+type txHookKind uint8
+
+const (
+	txHookCommit txHookKind = iota
+	txHookRollback
+	txHookAlways // rollback hooks of the rolled back savepoints
+)
+
+type txHook struct {
+	kind txHookKind
+	fn   TxHookFunc
+}
+
+// txHooks is the hooks scope of a single RunInTx call.
+type txHooks struct {
+	mu    sync.Mutex
+	hooks []txHook
+}
+
+// OnCommit registers the callback to be run after the outermost transaction started with RunInTx is committed.
+// If the callback is registered within a savepoint (i.e. nested RunInTx call), which is then rolled back, the callback is discarded.
+// Callbacks are run in the order of registration; see TxHookErrorHandler regarding their errors.
+//
+// Returns an error if the context has no transaction started with RunInTx, including the savepoints of the transactions started otherwise.
+func OnCommit(ctx context.Context, fn TxHookFunc) error {
+	return addTxHook(ctx, txHookCommit, fn)
+}
+
+// OnRollback registers the callback to be run after the outermost transaction started with RunInTx is rolled back.
+// If the callback is registered within a savepoint (i.e. nested RunInTx call), which is then rolled back,
+// the callback is run after the outermost transaction resolves either way.
+// Callbacks are run in the order of registration; see TxHookErrorHandler regarding their errors.
+//
+// Returns an error if the context has no transaction started with RunInTx, including the savepoints of the transactions started otherwise.
+func OnRollback(ctx context.Context, fn TxHookFunc) error {
+	return addTxHook(ctx, txHookRollback, fn)
+}
+
+func addTxHook(ctx context.Context, kind txHookKind, fn TxHookFunc) error {
+	h, ok := ctx.Value(txHooksContextKey{}).(*txHooks)
+	if !ok {
+		return errNoTxHooks
+	}
+
+	h.add(txHook{kind: kind, fn: fn})
+
+	return nil
+}
+
+// contextWithTxHooks returns a copy of the context carrying the given hooks scope.
+func contextWithTxHooks(ctx context.Context, h *txHooks) context.Context {
+	return context.WithValue(ctx, txHooksContextKey{}, h)
+}
+
+// txHooksFromContext returns the hooks scope of the context, if any.
+func txHooksFromContext(ctx context.Context) *txHooks {
+	h, _ := ctx.Value(txHooksContextKey{}).(*txHooks)
+	return h
+}
+
+func (h *txHooks) add(hooks ...txHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hooks...)
+}
+
+// resolve passes the hooks to the parent scope if there is one or runs them otherwise, depending on the transaction outcome.
+func (h *txHooks) resolve(ctx context.Context, parent *txHooks, committed bool) {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+
+	if parent != nil {
+		for _, hook := range hooks {
+			switch {
+			case committed:
+				parent.add(hook)
+			case hook.kind == txHookRollback:
+				parent.add(txHook{kind: txHookAlways, fn: hook.fn})
+			case hook.kind == txHookAlways:
+				parent.add(hook)
+			}
+		}
+
+		return
+	}
+
+	for _, hook := range hooks {
+		if hook.kind == txHookAlways || (hook.kind == txHookCommit) == committed {
+			runTxHook(ctx, hook.fn)
+		}
+	}
+}
+
+// runTxHook runs the hook reporting its error or panic with TxHookErrorHandler.
+func runTxHook(ctx context.Context, fn TxHookFunc) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportTxHookErr(ctx, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		reportTxHookErr(ctx, err)
+	}
+}
+
+func reportTxHookErr(ctx context.Context, err error) {
+	if handler := TxHookErrorHandler; handler != nil {
+		handler(ctx, err)
+	}
+}
@@ -0,0 +1,82 @@
+package xbun
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxHooks(t *testing.T) {
+	t.Parallel()
+
+	hookFunc := func(calls *[]string, name string) TxHookFunc {
+		return func(context.Context) error {
+			*calls = append(*calls, name)
+			return nil
+		}
+	}
+
+	run := func(t *testing.T, releaseNested, commit bool) []string {
+		t.Helper()
+
+		var calls []string
+
+		outer := &txHooks{}
+		ctx := contextWithTxHooks(context.Background(), outer)
+
+		require.NoError(t, OnCommit(ctx, hookFunc(&calls, "commit 1")))
+		require.NoError(t, OnRollback(ctx, hookFunc(&calls, "rollback 1")))
+
+		nested := &txHooks{}
+		nestedCtx := contextWithTxHooks(ctx, nested)
+
+		require.NoError(t, OnCommit(nestedCtx, hookFunc(&calls, "nested commit")))
+		require.NoError(t, OnRollback(nestedCtx, hookFunc(&calls, "nested rollback")))
+
+		nested.resolve(nestedCtx, outer, releaseNested)
+		require.Empty(t, calls)
+
+		require.NoError(t, OnCommit(ctx, hookFunc(&calls, "commit 2")))
+
+		outer.resolve(ctx, nil, commit)
+
+		return calls
+	}
+
+	require.Equal(t, []string{"commit 1", "nested commit", "commit 2"}, run(t, true, true))
+	require.Equal(t, []string{"rollback 1", "nested rollback"}, run(t, true, false))
+	require.Equal(t, []string{"commit 1", "nested rollback", "commit 2"}, run(t, false, true))
+	require.Equal(t, []string{"rollback 1", "nested rollback"}, run(t, false, false))
+}
+
+func TestOnCommit_noTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	require.Error(t, OnCommit(ctx, func(context.Context) error { return nil }))
+	require.Error(t, OnRollback(ctx, func(context.Context) error { return nil }))
+}
+
+// TestRunTxHook is not parallel, since it modifies the global TxHookErrorHandler.
+func TestRunTxHook(t *testing.T) {
+	var reported []error
+
+	handler := TxHookErrorHandler
+	TxHookErrorHandler = func(_ context.Context, err error) { reported = append(reported, err) }
+
+	t.Cleanup(func() { TxHookErrorHandler = handler })
+
+	errHook := errors.New("hook")
+	ctx := context.Background()
+
+	runTxHook(ctx, func(context.Context) error { return nil })
+	runTxHook(ctx, func(context.Context) error { return errHook })
+	runTxHook(ctx, func(context.Context) error { panic("boom") })
+
+	require.Len(t, reported, 2)
+	require.ErrorIs(t, reported[0], errHook)
+	require.EqualError(t, reported[1], "panic: boom")
+}
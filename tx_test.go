@@ -0,0 +1,30 @@
+package xbun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestTxOrDB(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := testDB()
+
+	_, ok := TxFromContext(ctx)
+	require.False(t, ok)
+	require.Equal(t, db, TxOrDB(ctx, db))
+
+	tx := bun.Tx{}
+	txCtx := ContextWithTx(ctx, tx)
+
+	ambient, ok := TxFromContext(txCtx)
+	require.True(t, ok)
+	require.Equal(t, tx, ambient)
+	require.Equal(t, tx, TxOrDB(txCtx, db))
+
+	require.Equal(t, tx, TxOrDB(ctx, tx))
+}
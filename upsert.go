@@ -0,0 +1,79 @@
+package xbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// UpsertResult holds the numbers of rows inserted and updated by Upsert.
+type UpsertResult struct {
+	Inserted int64
+	Updated  int64
+}
+
+// ExpectInserted checks if _all_ the given conditions are met for the number of inserted rows.
+// If any of the conditions is not met, it returns a corresponding xerr.AffectedRowsError for the first mismatch.
+func (r UpsertResult) ExpectInserted(cond ...AffectedCond) error {
+	if err := ExpectResult(affectedResult(r.Inserted), nil, cond...); err != nil {
+		return fmt.Errorf("inserted: %w", err)
+	}
+
+	return nil
+}
+
+// ExpectUpdated checks if _all_ the given conditions are met for the number of updated rows.
+// If any of the conditions is not met, it returns a corresponding xerr.AffectedRowsError for the first mismatch.
+func (r UpsertResult) ExpectUpdated(cond ...AffectedCond) error {
+	if err := ExpectResult(affectedResult(r.Updated), nil, cond...); err != nil {
+		return fmt.Errorf("updated: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert inserts the given (usually slice) model applying the given options, which should include one of OnConflictX options,
+// and reports the numbers of inserted and updated rows. Rows skipped with OnConflictDoNothing are not counted at all.
+//
+// It's PostgreSQL-only, since it relies on `RETURNING (xmax = 0) AS inserted` to tell the inserted rows from the updated ones.
+// For the same reason, the model values are not refreshed from the RETURNING clause, so you shouldn't use Returning option with it.
+func Upsert(ctx context.Context, db bun.IDB, model any, options ...QueryOption) (UpsertResult, error) {
+	if db.Dialect().Name() != dialect.PG {
+		return UpsertResult{}, errors.New("upsert: unsupported dialect " + db.Dialect().Name().String())
+	}
+
+	inserted := make([]bool, 0)
+	q := QueryOptions(db.NewInsert().Model(model), options...).Returning("(xmax = 0) AS inserted")
+
+	if err := ExpectSuccess(q.Scan(ctx, &inserted)); err != nil && !xerr.IsAffectedRows(err) {
+		return UpsertResult{}, err
+	}
+
+	var result UpsertResult
+
+	for _, ok := range inserted {
+		if ok {
+			result.Inserted++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// affectedResult is a sql.Result reporting the given number of affected rows.
+type affectedResult int64
+
+func (affectedResult) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported")
+}
+
+func (r affectedResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
@@ -0,0 +1,59 @@
+package xbun
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestUpsert(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+
+	captured := func(q bun.Query) { q.(*bun.InsertQuery).Conn(captureConn{queries: &queries}) }
+	models := []*testModel{{PK: PK[int64]{ID: 1}, Name: "a"}, {PK: PK[int64]{ID: 2}, Name: "b"}}
+
+	_, err := Upsert(context.Background(), testDB(), &models, OnConflictUpdate(ConflictColumns("id"), "name"), captured)
+	require.ErrorIs(t, err, errCaptured)
+
+	require.Equal(t, []string{
+		`INSERT INTO "models" AS "m" ("name", "id") VALUES ('a', 1), ('b', 2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" ` +
+			`RETURNING (xmax = 0) AS inserted`,
+	}, queries)
+}
+
+func TestUpsert_unsupportedDialect(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, db := range []*bun.DB{bun.NewDB(&sql.DB{}, sqlitedialect.New()), testMySQLDB()} {
+		_, err := Upsert(ctx, db, &testModel{}, OnConflictDoNothing(ConflictTarget{}))
+		require.ErrorContains(t, err, "unsupported dialect "+db.Dialect().Name().String())
+	}
+}
+
+func TestUpsertResult(t *testing.T) {
+	t.Parallel()
+
+	r := UpsertResult{Inserted: 2, Updated: 1}
+
+	require.NoError(t, r.ExpectInserted())
+	require.NoError(t, r.ExpectInserted(AffectedExactly(2)))
+	require.NoError(t, r.ExpectUpdated(AffectedExactly(1), AffectedLT(2)))
+
+	err := r.ExpectInserted(AffectedExactly(3))
+	require.ErrorAs(t, err, &xerr.AffectedRowsError{})
+	require.ErrorContains(t, err, "inserted")
+
+	err = r.ExpectUpdated(AffectedGT(1))
+	require.ErrorAs(t, err, &xerr.AffectedRowsError{})
+	require.ErrorContains(t, err, "updated")
+}
@@ -0,0 +1,405 @@
+package xbuntest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/bun/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureRefPrefix starts the reference to another fixture primary key, e.g. `"@alice"` or `"@users.alice"`;
+// `"@@"` escapes the literal `@`.
+const fixtureRefPrefix = "@"
+
+// Fixtures is a set of labeled model rows to be loaded into the test database.
+//
+// Fixtures are defined in YAML (or JSON, which is a subset of it) keyed by the table (or model) names and then by the labels,
+// which are unique within the table:
+//
+//	users:
+//	  alice:
+//	    name: Alice
+//	posts:
+//	  hello:
+//	    title: Hello
+//	    user_id: "@alice"
+//
+// Values are the column values, where `"@label"` is replaced with the primary key of the referenced fixture,
+// and objects or arrays are stored as JSON. The label is qualified with the table (e.g. `"@users.alice"`) if it's used by several tables.
+// The fixtures with composite primary keys can't be referenced. Columns, which are omitted, are populated by the model hooks on insert
+// (e.g. xbun.Timestamps or the generated primary keys like xbun.PKUUIDv7), so the rows are inserted with the usual bun insert queries.
+//
+// The models must be known to the database, which is the case for the registered ones (see RegisterModels and WithModels).
+type Fixtures struct {
+	rows []fixtureRow
+}
+
+type fixtureRow struct {
+	fixtureKey
+
+	values map[string]any
+}
+
+// fixtureKey identifies the fixture by the table (as it's named in the fixtures) and the label.
+type fixtureKey struct {
+	table string
+	label string
+}
+
+func (k fixtureKey) String() string {
+	return k.table + "." + k.label
+}
+
+// ParseFixtures parses the fixtures from the YAML or JSON data.
+func ParseFixtures(data []byte) (*Fixtures, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse fixtures: %w", err)
+	}
+
+	f := &Fixtures{}
+	if len(doc.Content) == 0 {
+		return f, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("parse fixtures: tables mapping expected")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		table, rows := root.Content[i].Value, root.Content[i+1]
+		if rows.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("parse fixtures: %s: labels mapping expected", table)
+		}
+
+		for j := 0; j+1 < len(rows.Content); j += 2 {
+			row := fixtureRow{fixtureKey: fixtureKey{table: table, label: rows.Content[j].Value}}
+
+			if err := rows.Content[j+1].Decode(&row.values); err != nil {
+				return nil, fmt.Errorf("parse fixtures: %s: %w", row.fixtureKey, err)
+			}
+
+			f.add(row)
+		}
+	}
+
+	return f, nil
+}
+
+// ParseFixturesFile works just like ParseFixtures, but reads the data from the file.
+func ParseFixturesFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFixtures(data)
+}
+
+// MustParseFixtures works just like ParseFixtures, but panics on error. Useful for package-level base fixture sets.
+func MustParseFixtures(data []byte) *Fixtures {
+	f, err := ParseFixtures(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+// Layer returns a new set of the fixtures layered with the given ones: new labels of the tables are added,
+// while the values of the existing ones are overridden column by column. Neither of the sets is modified.
+func (f *Fixtures) Layer(layers ...*Fixtures) *Fixtures {
+	res := &Fixtures{rows: make([]fixtureRow, 0, len(f.rows))}
+
+	for _, set := range append([]*Fixtures{f}, layers...) {
+		for _, row := range set.rows {
+			res.add(row)
+		}
+	}
+
+	return res
+}
+
+// add adds the row copy to the set, merging it with the existing row of the same table and label.
+func (f *Fixtures) add(row fixtureRow) {
+	for i := range f.rows {
+		if existing := &f.rows[i]; existing.fixtureKey == row.fixtureKey {
+			for column, value := range row.values {
+				existing.values[column] = value
+			}
+
+			return
+		}
+	}
+
+	values := make(map[string]any, len(row.values))
+	for column, value := range row.values {
+		values[column] = value
+	}
+
+	f.rows = append(f.rows, fixtureRow{fixtureKey: row.fixtureKey, values: values})
+}
+
+// Load inserts the fixtures within the test transaction and returns the registry of the inserted models.
+// Rows are inserted in the order of definition, except the ones referencing the fixtures defined later, which are postponed.
+// Any error fails the test immediately.
+func (f *Fixtures) Load(t testing.TB, db DB) *Registry {
+	t.Helper()
+
+	r, err := f.load(db)
+	if err != nil {
+		t.Fatalf("xbuntest: load fixtures: %v", err)
+	}
+
+	return r
+}
+
+func (f *Fixtures) load(db DB) (*Registry, error) {
+	rows, err := f.resolve(db)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{models: make(map[fixtureKey]any, len(rows)), pks: make(map[fixtureKey]any, len(rows))}
+	pending := rows
+
+	for len(pending) > 0 {
+		var postponed []resolvedRow
+
+		for _, row := range pending {
+			if !r.resolvable(row) {
+				postponed = append(postponed, row)
+				continue
+			}
+
+			if err = r.insert(db, row); err != nil {
+				return nil, fmt.Errorf("%s: %w", row.fixtureKey, err)
+			}
+		}
+
+		if len(postponed) == len(pending) {
+			return nil, fmt.Errorf("%s: circular reference", postponed[0].fixtureKey)
+		}
+
+		pending = postponed
+	}
+
+	return r, nil
+}
+
+// resolvedRow is fixtureRow with the model table and the references resolved.
+type resolvedRow struct {
+	fixtureRow
+
+	table *schema.Table
+	refs  map[string]fixtureKey // by column
+}
+
+// resolve resolves the tables and the references of the rows, so the invalid ones are reported before inserting anything.
+func (f *Fixtures) resolve(db DB) ([]resolvedRow, error) {
+	rows := make([]resolvedRow, len(f.rows))
+	tables := make(map[fixtureKey]*schema.Table, len(f.rows))
+
+	for i, row := range f.rows {
+		table := db.Dialect().Tables().ByName(row.table)
+		if table == nil {
+			table = db.Dialect().Tables().ByModel(row.table)
+		}
+
+		if table == nil {
+			return nil, fmt.Errorf("%s: unknown table %s", row.fixtureKey, row.table)
+		}
+
+		rows[i] = resolvedRow{fixtureRow: row, table: table}
+		tables[row.fixtureKey] = table
+	}
+
+	for i := range rows {
+		row := &rows[i]
+
+		for column, value := range row.values {
+			ref, ok := fixtureRef(value)
+			if !ok {
+				continue
+			}
+
+			key, err := f.resolveRef(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", row.fixtureKey, column, err)
+			}
+
+			if pks := tables[key].PKs; len(pks) != 1 {
+				return nil, fmt.Errorf("%s: %s: fixture %s can't be referenced, since it has %d primary keys", row.fixtureKey, column, key, len(pks))
+			}
+
+			if row.refs == nil {
+				row.refs = make(map[string]fixtureKey)
+			}
+
+			row.refs[column] = key
+		}
+	}
+
+	return rows, nil
+}
+
+// resolveRef returns the key of the fixture referenced either by the label or by the label qualified with the table.
+func (f *Fixtures) resolveRef(ref string) (fixtureKey, error) {
+	var found []fixtureKey
+
+	for _, row := range f.rows {
+		if row.label == ref || row.table+"."+row.label == ref {
+			found = append(found, row.fixtureKey)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return fixtureKey{}, fmt.Errorf("unknown fixture %s", ref)
+	case 1:
+		return found[0], nil
+	default:
+		return fixtureKey{}, fmt.Errorf("ambiguous fixture %s, qualify it with the table", ref)
+	}
+}
+
+// Registry holds the models inserted by Fixtures.Load by their tables and labels.
+type Registry struct {
+	models map[fixtureKey]any
+	pks    map[fixtureKey]any
+}
+
+// Get returns the loaded model of type M (e.g. `*User`) by its label.
+// The label is looked up among the fixtures of the M's table only.
+func Get[M any](r *Registry, label string) (M, error) {
+	var (
+		zero  M
+		found bool
+	)
+
+	for key, model := range r.models {
+		if key.label != label {
+			continue
+		}
+
+		if m, ok := model.(M); ok {
+			return m, nil
+		}
+
+		found = true
+	}
+
+	if found {
+		return zero, fmt.Errorf("fixture %s is not %T", label, zero)
+	}
+
+	return zero, fmt.Errorf("unknown fixture %s", label)
+}
+
+// MustGet works just like Get, but fails the test on error.
+func MustGet[M any](t testing.TB, r *Registry, label string) M {
+	t.Helper()
+
+	m, err := Get[M](r, label)
+	if err != nil {
+		t.Fatalf("xbuntest: %v", err)
+	}
+
+	return m
+}
+
+// resolvable checks if all the references of the row are inserted already.
+func (r *Registry) resolvable(row resolvedRow) bool {
+	for _, key := range row.refs {
+		if _, ok := r.pks[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Registry) insert(db DB, row resolvedRow) error {
+	model := reflect.New(row.table.Type)
+	strct := model.Elem()
+
+	for column, value := range row.values {
+		field, err := row.table.Field(column)
+		if err != nil {
+			return err
+		}
+
+		if key, ok := row.refs[column]; ok {
+			value = r.pks[key]
+		} else if value, err = fixtureValue(value); err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+
+		if err = setField(field, strct, value); err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+	}
+
+	if _, err := db.NewInsert().Model(model.Interface()).Exec(context.Background()); err != nil {
+		return err
+	}
+
+	r.models[row.fixtureKey] = model.Interface()
+
+	if len(row.table.PKs) == 1 {
+		r.pks[row.fixtureKey] = row.table.PKs[0].Value(strct).Interface()
+	}
+
+	return nil
+}
+
+// fixtureRef returns the label of the referenced fixture if the value is a reference.
+func fixtureRef(value any) (string, bool) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, fixtureRefPrefix) || strings.HasPrefix(s, fixtureRefPrefix+fixtureRefPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(s, fixtureRefPrefix), true
+}
+
+// fixtureValue converts the decoded YAML value to the one suitable for bun's scanners.
+func fixtureValue(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case string:
+		return strings.TrimPrefix(v, fixtureRefPrefix), nil // fixtureRef has excluded the references, so it's either escaped or not prefixed
+	case map[string]any, []any:
+		return json.Marshal(v)
+	default:
+		return v, nil
+	}
+}
+
+// setField sets the model field to the value, either directly if the types match or with bun's scanner otherwise.
+func setField(field *schema.Field, strct reflect.Value, value any) error {
+	if value != nil {
+		rv := reflect.ValueOf(value)
+		fv := field.Value(strct)
+
+		switch {
+		case rv.Type().AssignableTo(fv.Type()):
+			fv.Set(rv)
+			return nil
+		case fv.Kind() != reflect.String && rv.CanConvert(fv.Type()) && rv.Kind() != reflect.String:
+			fv.Set(rv.Convert(fv.Type()))
+			return nil
+		}
+	}
+
+	return field.ScanValue(strct, value)
+}
@@ -0,0 +1,140 @@
+package xbuntest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+)
+
+type testUser struct {
+	bun.BaseModel `bun:"table:users,alias:u"`
+
+	xbun.PKAutoIncrement[int64]
+	xbun.Timestamps
+
+	Name  string `bun:"name,notnull"`
+	Admin bool   `bun:"admin,notnull"`
+}
+
+type testPost struct {
+	bun.BaseModel `bun:"table:posts,alias:p"`
+
+	xbun.PKUUIDv7
+
+	UserID int64          `bun:"user_id,notnull"`
+	Title  string         `bun:"title,notnull"`
+	Meta   map[string]any `bun:"meta,type:json"`
+}
+
+type testMembership struct {
+	bun.BaseModel `bun:"table:memberships,alias:m"`
+
+	UserID  int64 `bun:"user_id,pk"`
+	GroupID int64 `bun:"group_id,pk"`
+}
+
+var testBaseFixtures = MustParseFixtures([]byte(`
+posts:
+  hello:
+    title: Hello
+    user_id: "@alice"
+    meta: {tags: [a, b]}
+users:
+  alice:
+    name: Alice
+  bob:
+    name: "@@bob"
+`))
+
+func TestFixtures_Load(t *testing.T) {
+	t.Parallel()
+
+	db := New(t, WithModels((*testUser)(nil), (*testPost)(nil)))
+
+	fx := testBaseFixtures.Layer(MustParseFixtures([]byte(`
+users:
+  alice: {admin: true}
+posts:
+  bye: {title: Bye, user_id: "@users.bob"}
+  bob: {title: Bob, user_id: "@users.bob"}
+`)))
+
+	r := fx.Load(t, db)
+
+	alice := MustGet[*testUser](t, r, "alice")
+	require.NotZero(t, alice.ID)
+	require.Equal(t, "Alice", alice.Name)
+	require.True(t, alice.Admin)
+	require.False(t, alice.CreatedAt.IsZero())
+
+	bob := MustGet[*testUser](t, r, "bob")
+	require.Equal(t, "@bob", bob.Name)
+	require.False(t, bob.Admin)
+
+	hello := MustGet[*testPost](t, r, "hello")
+	require.Equal(t, uuid.Version(7), hello.ID.Version())
+	require.Equal(t, alice.ID, hello.UserID)
+
+	bye := MustGet[*testPost](t, r, "bye")
+	require.Equal(t, bob.ID, bye.UserID)
+
+	// The same label is used in both tables.
+	bobPost := MustGet[*testPost](t, r, "bob")
+	require.Equal(t, "Bob", bobPost.Title)
+	require.Equal(t, bob.ID, bobPost.UserID)
+	require.Equal(t, "@bob", MustGet[*testUser](t, r, "bob").Name)
+
+	var post testPost
+
+	require.NoError(t, db.NewSelect().Model(&post).Where("?TableAlias.id = ?", hello.ID).Scan(context.Background()))
+	require.Equal(t, map[string]any{"tags": []any{"a", "b"}}, post.Meta)
+
+	_, err := Get[*testPost](r, "alice")
+	require.Error(t, err)
+
+	_, err = Get[*testUser](r, "carol")
+	require.Error(t, err)
+}
+
+func TestFixtures_error(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseFixtures([]byte(`[1, 2]`))
+	require.Error(t, err)
+
+	db := New(t, WithModels((*testUser)(nil), (*testPost)(nil), (*testMembership)(nil)))
+
+	for _, data := range []string{
+		`{"posts": {"hello": {"title": "x", "user_id": "@nobody"}}}`,
+		`{"unknown": {"x": {}}}`,
+		`{"users": {"x": {"unknown": 1}}}`,
+		`{"users": {"x": {"name": "x"}}, "posts": {"x": {"title": "x", "user_id": "@x"}}}`,
+		`{"memberships": {"m": {"user_id": 1, "group_id": 1}}, "posts": {"x": {"title": "x", "user_id": "@m"}}}`,
+		`{"posts": {"a": {"title": "@b", "user_id": "@b"}, "b": {"title": "x", "user_id": "@a"}}}`,
+	} {
+		_, err = MustParseFixtures([]byte(data)).load(db)
+		require.Error(t, err, data)
+	}
+}
+
+func TestFixtures_compositePK(t *testing.T) {
+	t.Parallel()
+
+	db := New(t, WithModels((*testUser)(nil), (*testMembership)(nil)))
+
+	r := MustParseFixtures([]byte(`
+memberships:
+  alice: {user_id: "@users.alice", group_id: 1}
+users:
+  alice: {name: Alice}
+`)).Load(t, db)
+
+	membership := MustGet[*testMembership](t, r, "alice")
+	require.Equal(t, MustGet[*testUser](t, r, "alice").ID, membership.UserID)
+	require.EqualValues(t, 1, membership.GroupID)
+}
@@ -0,0 +1,200 @@
+package xbuntest
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/sqlnorm"
+)
+
+var _ bun.QueryHook = (*Recorder)(nil)
+
+// RecordedQuery is the query executed while recording.
+type RecordedQuery struct {
+	Query string // the formatted query
+	// Args are the arguments of the raw query (see bun.DB.NewRaw), formatted into Query.
+	// They are nil for the other queries, which are formatted by bun.
+	Args      []any
+	Operation string // e.g. SELECT, INSERT, see bun.QueryEvent.Operation
+	Duration  time.Duration
+	// RowsAffected is the number of rows affected (or scanned) by the query, or -1 if the result is not available.
+	RowsAffected int64
+	Err          error
+}
+
+// Normalized returns the query with the literal values replaced by placeholders, so the same queries executed
+// with different arguments are equal.
+func (q RecordedQuery) Normalized() string {
+	return sqlnorm.Normalize(q.Query)
+}
+
+// Recorder is bun.QueryHook recording the executed queries. It's safe for concurrent use.
+//
+// Every database opened by New routes the queries to the recorder carried by their context (see Recorder.Context),
+// so the recorders of parallel tests don't mix up even with the shared PostgreSQL database.
+// To record all the queries of some other database, add the recorder to it with bun.DB.AddQueryHook.
+type Recorder struct {
+	mu      sync.Mutex
+	queries []RecordedQuery
+}
+
+// NewRecorder returns an empty query recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type recorderCtxKey struct{}
+
+// Context returns a copy of the context carrying the recorder.
+// Queries executed with it against the databases opened by New are recorded.
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recorderCtxKey{}, r)
+}
+
+// BeforeQuery implements bun.QueryHook.
+func (r *Recorder) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery implements bun.QueryHook.
+func (r *Recorder) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	q := RecordedQuery{
+		Query:        event.Query,
+		Args:         event.QueryArgs,
+		Operation:    event.Operation(),
+		Duration:     time.Since(event.StartTime),
+		RowsAffected: -1,
+		Err:          event.Err,
+	}
+
+	if event.Result != nil {
+		if n, err := event.Result.RowsAffected(); err == nil {
+			q.RowsAffected = n
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries = append(r.queries, q)
+}
+
+// Queries returns a copy of the recorded queries in the execution order.
+func (r *Recorder) Queries() []RecordedQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedQuery(nil), r.queries...)
+}
+
+// Reset forgets the recorded queries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries = nil
+}
+
+// AssertQueryCount asserts that exactly n queries are recorded.
+func (r *Recorder) AssertQueryCount(t testing.TB, n int) bool {
+	t.Helper()
+
+	queries := r.Queries()
+	if len(queries) == n {
+		return true
+	}
+
+	t.Errorf("xbuntest: expected %d queries, got %d:\n%s", n, len(queries), formatQueries(queries))
+
+	return false
+}
+
+// AssertNoNPlusOne asserts that no normalized query (see RecordedQuery.Normalized) is recorded more than maxRepeats times,
+// which usually means the query is executed in a loop, e.g. for every row of some previous query.
+// Set maxRepeats according to the expected number of round trips, e.g. the number of chunks for xquery.Selector.Iter.
+func (r *Recorder) AssertNoNPlusOne(t testing.TB, maxRepeats int) bool {
+	t.Helper()
+
+	var (
+		counts = make(map[string]int)
+		order  []string
+	)
+
+	for _, q := range r.Queries() {
+		norm := q.Normalized()
+		if counts[norm] == 0 {
+			order = append(order, norm)
+		}
+
+		counts[norm]++
+	}
+
+	ok := true
+
+	for _, norm := range order {
+		if n := counts[norm]; n > maxRepeats {
+			t.Errorf("xbuntest: query is executed %d times (max %d): %s", n, maxRepeats, norm)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// AssertQueryMatches asserts that at least one of the recorded queries matches the regular expression.
+func (r *Recorder) AssertQueryMatches(t testing.TB, pattern string) bool {
+	t.Helper()
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.Errorf("xbuntest: invalid pattern %q: %v", pattern, err)
+		return false
+	}
+
+	queries := r.Queries()
+	for _, q := range queries {
+		if re.MatchString(q.Query) {
+			return true
+		}
+	}
+
+	t.Errorf("xbuntest: no query matches %q:\n%s", pattern, formatQueries(queries))
+
+	return false
+}
+
+func formatQueries(queries []RecordedQuery) string {
+	if len(queries) == 0 {
+		return "\t(none)"
+	}
+
+	lines := make([]string, len(queries))
+	for i, q := range queries {
+		lines[i] = "\t" + q.Query
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// -----------------------------------------------------------------------------------------------------------------------------------------
+
+var _ bun.QueryHook = recorderHook{}
+
+// recorderHook routes the queries to the recorder carried by their context.
+type recorderHook struct{}
+
+func (recorderHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (recorderHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if r, ok := ctx.Value(recorderCtxKey{}).(*Recorder); ok {
+		r.AfterQuery(ctx, event)
+	}
+}
@@ -0,0 +1,75 @@
+package xbuntest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xquery"
+)
+
+// fakeT captures the errors of the assertions expected to fail.
+type fakeT struct {
+	testing.TB
+
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	db := New(t)
+	ctx := context.Background()
+
+	items := make([]*testItem, 5)
+	for i := range items {
+		items[i] = &testItem{PK: xbun.PK[int64]{ID: int64(i + 1)}, Name: fmt.Sprint("item", i+1)}
+	}
+
+	_, err := db.NewInsert().Model(&items).Exec(ctx)
+	require.NoError(t, err)
+
+	rec := NewRecorder()
+	chunks := 0
+
+	iter := func(context.Context, bun.IDB, []*testItem) (bool, error) {
+		chunks++
+		return true, nil
+	}
+
+	err = (&xquery.Select[int64, *testItem, []*testItem]{}).Iter(rec.Context(ctx), db, 2, iter)
+	require.NoError(t, err)
+	require.Equal(t, 3, chunks)
+
+	require.True(t, rec.AssertQueryCount(t, 3))
+	require.True(t, rec.AssertNoNPlusOne(t, 3))
+	require.True(t, rec.AssertQueryMatches(t, `LIMIT 2$`))
+
+	queries := rec.Queries()
+	require.Equal(t, "SELECT", queries[0].Operation)
+	require.EqualValues(t, 2, queries[0].RowsAffected)
+	require.EqualValues(t, 1, queries[2].RowsAffected)
+	require.Equal(t, queries[1].Normalized(), queries[2].Normalized())
+
+	ft := &fakeT{TB: t}
+	require.False(t, rec.AssertQueryCount(ft, 1))
+	require.False(t, rec.AssertNoNPlusOne(ft, 1))
+	require.False(t, rec.AssertQueryMatches(ft, `^DELETE`))
+	require.Len(t, ft.errors, 3)
+
+	// Queries executed without the recorder context are not recorded.
+	rec.Reset()
+	_, err = db.NewSelect().Model((*testItem)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Empty(t, rec.Queries())
+}
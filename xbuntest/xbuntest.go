@@ -0,0 +1,211 @@
+package xbuntest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"github.com/uptrace/bun/driver/sqliteshim"
+
+	"github.com/heffcodex/xbun"
+)
+
+// EnvPostgresDSN is the environment variable with the PostgreSQL DSN.
+// If it's set, tests are run against the PostgreSQL database instead of the in-memory SQLite one.
+const EnvPostgresDSN = "XBUNTEST_POSTGRES_DSN"
+
+var (
+	registryMu sync.Mutex
+	registry   []any
+
+	sqliteSeq atomic.Uint64
+
+	postgresMu  sync.Mutex
+	postgresDBs = make(map[string]*postgresDB)
+)
+
+// postgresDB is the PostgreSQL database shared by all the tests of the process along with its created tables.
+type postgresDB struct {
+	db     *bun.DB
+	tables map[reflect.Type]struct{}
+}
+
+// DB is the isolated test database handle.
+// All the queries run with it (as bun.IDB) are the part of the test transaction, which is rolled back at the test cleanup.
+type DB struct {
+	bun.Tx
+
+	// Base is the underlying database. Queries run with it directly are NOT isolated.
+	// With SQLite, it's a fresh in-memory database for every New call, with PostgreSQL it's shared by all the tests.
+	Base *bun.DB
+}
+
+// Option configures New.
+type Option func(c *config)
+
+type config struct {
+	models []any
+	dsn    string
+}
+
+// WithModels adds the models, which tables are created in addition to the registered ones (see RegisterModels).
+func WithModels(models ...any) Option {
+	return func(c *config) {
+		c.models = append(c.models, models...)
+	}
+}
+
+// WithPostgresDSN sets the PostgreSQL DSN overriding the EnvPostgresDSN environment variable.
+func WithPostgresDSN(dsn string) Option {
+	return func(c *config) {
+		c.dsn = dsn
+	}
+}
+
+// RegisterModels registers the models (e.g. `(*User)(nil)`), which tables are created for every test database.
+// It's meant to be called from TestMain or init functions of the test packages.
+func RegisterModels(models ...any) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry = append(registry, models...)
+}
+
+// New returns the test database handle with the tables of the registered models created.
+// The handle is a transaction, which is rolled back at the test cleanup, so it's safe to use with t.Parallel.
+func New(t testing.TB, options ...Option) DB {
+	t.Helper()
+
+	registryMu.Lock()
+	c := config{models: append([]any(nil), registry...), dsn: os.Getenv(EnvPostgresDSN)}
+	registryMu.Unlock()
+
+	for _, opt := range options {
+		opt(&c)
+	}
+
+	var (
+		base *bun.DB
+		err  error
+	)
+
+	if c.dsn != "" {
+		base, err = openPostgres(c.dsn, c.models)
+	} else {
+		base, err = openSQLite(t, c.models)
+	}
+
+	if err != nil {
+		t.Fatalf("xbuntest: open database: %v", err)
+	}
+
+	tx, err := base.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("xbuntest: begin transaction: %v", err)
+	}
+
+	t.Cleanup(func() { rollback(t, tx) })
+
+	return DB{Tx: tx, Base: base}
+}
+
+// Savepoint returns the nested test database handle, which is rolled back to the savepoint at the (sub)test cleanup.
+// Unlike New, it's not safe for parallel subtests, since they would share the same transaction.
+func (db DB) Savepoint(t testing.TB) DB {
+	t.Helper()
+
+	sp, err := db.Tx.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("xbuntest: savepoint: %v", err)
+	}
+
+	t.Cleanup(func() { rollback(t, sp) })
+
+	return DB{Tx: sp, Base: db.Base}
+}
+
+// Context returns a copy of the context carrying the test transaction as the ambient one (see xbun.ContextWithDetachedTx),
+// so the code under test joins it even if it's given the base database.
+// The outermost xbun.RunInTx calls of the code under test run their OnCommit and OnRollback hooks once their savepoints resolve,
+// as if they were not joining the test transaction, which is never committed.
+func (db DB) Context(ctx context.Context) context.Context {
+	return xbun.ContextWithDetachedTx(ctx, db.Tx)
+}
+
+func rollback(t testing.TB, tx bun.Tx) {
+	t.Helper()
+
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("xbuntest: rollback: %v", err)
+	}
+}
+
+// openSQLite opens a fresh in-memory SQLite database closed at the test cleanup.
+func openSQLite(t testing.TB, models []any) (*bun.DB, error) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:xbuntest_%d?mode=memory&cache=shared", sqliteSeq.Add(1))
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	db.AddQueryHook(recorderHook{})
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db, createTables(db, models, nil)
+}
+
+// openPostgres returns the PostgreSQL database shared by the tests with the DSN, creating the missing tables.
+func openPostgres(dsn string, models []any) (*bun.DB, error) {
+	postgresMu.Lock()
+	defer postgresMu.Unlock()
+
+	pg, ok := postgresDBs[dsn]
+	if !ok {
+		pg = &postgresDB{
+			db:     bun.NewDB(sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn))), pgdialect.New()),
+			tables: make(map[reflect.Type]struct{}),
+		}
+		pg.db.AddQueryHook(recorderHook{})
+		postgresDBs[dsn] = pg
+	}
+
+	return pg.db, createTables(pg.db, models, pg.tables)
+}
+
+// createTables creates the tables of the models, skipping the ones in the created set if it's given.
+func createTables(db *bun.DB, models []any, created map[reflect.Type]struct{}) error {
+	ctx := context.Background()
+
+	db.RegisterModel(models...)
+
+	for _, model := range models {
+		typ := reflect.TypeOf(model)
+		if _, ok := created[typ]; ok {
+			continue
+		}
+
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return fmt.Errorf("create table for %T: %w", model, err)
+		}
+
+		if created != nil {
+			created[typ] = struct{}{}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package xbuntest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xquery"
+)
+
+type testItem struct {
+	bun.BaseModel `bun:"table:items,alias:i"`
+
+	xbun.PK[int64]
+
+	Name string `bun:"name,notnull"`
+}
+
+func init() {
+	RegisterModels((*testItem)(nil))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	// Every subtest inserts the same primary key, which would conflict without isolation.
+	for _, name := range []string{"a", "b", "c"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := New(t)
+			ctx := context.Background()
+
+			res, err := db.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 1}, Name: name}).Exec(ctx)
+			require.NoError(t, xbun.ExpectResult(res, err, xbun.AffectedExactly(1)))
+
+			items, err := (&xquery.Select[int64, *testItem, []*testItem]{}).All(db.Context(ctx), db.Base)
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			require.Equal(t, name, items[0].Name)
+		})
+	}
+}
+
+// manualCleanupT is testing.TB running the registered cleanups on demand.
+type manualCleanupT struct {
+	*testing.T
+
+	cleanups []func()
+}
+
+func (t *manualCleanupT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+// cleanupLast runs the last registered cleanup only.
+func (t *manualCleanupT) cleanupLast() {
+	f := t.cleanups[len(t.cleanups)-1]
+	t.cleanups = t.cleanups[:len(t.cleanups)-1]
+	f()
+}
+
+func (t *manualCleanupT) cleanupAll() {
+	for len(t.cleanups) > 0 {
+		t.cleanupLast()
+	}
+}
+
+func TestNew_rollback(t *testing.T) {
+	t.Parallel()
+
+	mt := &manualCleanupT{T: t}
+	t.Cleanup(mt.cleanupAll)
+
+	db := New(mt)
+	ctx := context.Background()
+
+	_, err := db.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 1}, Name: "rolled back"}).Exec(ctx)
+	require.NoError(t, err)
+
+	n, err := db.NewSelect().Model((*testItem)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// The last cleanup registered by New is the rollback of the test transaction, the base database is still open after it.
+	mt.cleanupLast()
+
+	n, err = db.Base.NewSelect().Model((*testItem)(nil)).Count(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestDB_Savepoint(t *testing.T) {
+	t.Parallel()
+
+	db := New(t)
+	ctx := context.Background()
+
+	count := func(t *testing.T) int {
+		t.Helper()
+
+		n, err := db.NewSelect().Model((*testItem)(nil)).Count(ctx)
+		require.NoError(t, err)
+
+		return n
+	}
+
+	_, err := db.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 1}, Name: "outer"}).Exec(ctx)
+	require.NoError(t, err)
+
+	t.Run("nested", func(t *testing.T) {
+		sp := db.Savepoint(t)
+
+		_, err := sp.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 2}, Name: "nested"}).Exec(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, count(t))
+	})
+
+	require.Equal(t, 1, count(t))
+}
+
+func TestRunInTx(t *testing.T) {
+	t.Parallel()
+
+	db := New(t)
+	ctx := db.Context(context.Background())
+
+	var calls []string
+
+	hook := func(name string) xbun.TxHookFunc {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	err := xbun.RunInTx(ctx, db.Base, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.NoError(t, xbun.OnCommit(ctx, hook("commit")))
+
+		_, err := tx.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 1}, Name: "kept"}).Exec(ctx)
+		require.NoError(t, err)
+
+		err = xbun.RunInTx(ctx, db.Base, nil, func(ctx context.Context, tx bun.Tx) error {
+			require.NoError(t, xbun.OnCommit(ctx, hook("nested commit")))
+			require.NoError(t, xbun.OnRollback(ctx, hook("nested rollback")))
+
+			_, err := tx.NewInsert().Model(&testItem{PK: xbun.PK[int64]{ID: 2}, Name: "discarded"}).Exec(ctx)
+			require.NoError(t, err)
+
+			return context.Canceled
+		})
+		require.ErrorIs(t, err, context.Canceled)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"commit", "nested rollback"}, calls)
+
+	var names []string
+
+	require.NoError(t, db.NewSelect().Model((*testItem)(nil)).Column("name").Scan(ctx, &names))
+	require.Equal(t, []string{"kept"}, names)
+}
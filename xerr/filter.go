@@ -0,0 +1,35 @@
+package xerr
+
+import "errors"
+
+type FilterError struct {
+	field  string
+	reason string
+}
+
+func IsFilter(err error) bool {
+	return errors.As(err, &FilterError{})
+}
+
+func ErrFilter(field, reason string) error {
+	return FilterError{
+		field:  field,
+		reason: reason,
+	}
+}
+
+func (e FilterError) Error() string {
+	if e.field == "" {
+		return "filter: " + e.reason
+	}
+
+	return "filter " + e.field + ": " + e.reason
+}
+
+func (e FilterError) Field() string {
+	return e.field
+}
+
+func (e FilterError) Reason() string {
+	return e.reason
+}
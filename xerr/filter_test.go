@@ -0,0 +1,23 @@
+package xerr
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsFilter(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsFilter(nil))
+	assert.False(t, IsFilter(sql.ErrNoRows))
+
+	err := ErrFilter("status", "unknown field")
+
+	assert.True(t, IsFilter(err))
+	assert.True(t, IsFilter(fmt.Errorf("err: %w", err)))
+	assert.Equal(t, "filter status: unknown field", err.Error())
+	assert.Equal(t, "filter: invalid JSON", ErrFilter("", "invalid JSON").Error())
+}
@@ -0,0 +1,24 @@
+package xerr
+
+import "errors"
+
+// LockNotAvailableError is returned when a row lock can't be acquired immediately, e.g. with `FOR UPDATE NOWAIT`.
+type LockNotAvailableError struct {
+	err error
+}
+
+func IsLockNotAvailable(err error) bool {
+	return errors.As(err, &LockNotAvailableError{})
+}
+
+func ErrLockNotAvailable(err error) LockNotAvailableError {
+	return LockNotAvailableError{err: err}
+}
+
+func (e LockNotAvailableError) Error() string {
+	return "lock not available: " + e.err.Error()
+}
+
+func (e LockNotAvailableError) Unwrap() error {
+	return e.err
+}
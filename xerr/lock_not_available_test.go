@@ -0,0 +1,23 @@
+package xerr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLockNotAvailable(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsLockNotAvailable(nil))
+	assert.False(t, IsLockNotAvailable(sql.ErrNoRows))
+
+	err := ErrLockNotAvailable(errors.New("error"))
+
+	assert.True(t, IsLockNotAvailable(err))
+	assert.True(t, IsLockNotAvailable(fmt.Errorf("err: %w", err)))
+	assert.True(t, IsLockNotAvailable(ErrQueryExecution(err)))
+}
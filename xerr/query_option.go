@@ -0,0 +1,31 @@
+package xerr
+
+import "errors"
+
+type QueryOptionError struct {
+	option string
+	reason string
+}
+
+func IsQueryOption(err error) bool {
+	return errors.As(err, &QueryOptionError{})
+}
+
+func ErrQueryOption(option, reason string) error {
+	return QueryOptionError{
+		option: option,
+		reason: reason,
+	}
+}
+
+func (e QueryOptionError) Error() string {
+	return "query option " + e.option + ": " + e.reason
+}
+
+func (e QueryOptionError) Option() string {
+	return e.option
+}
+
+func (e QueryOptionError) Reason() string {
+	return e.reason
+}
@@ -0,0 +1,22 @@
+package xerr
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsQueryOption(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsQueryOption(nil))
+	assert.False(t, IsQueryOption(sql.ErrNoRows))
+
+	err := ErrQueryOption("Limit", "unsupported query type")
+
+	assert.True(t, IsQueryOption(err))
+	assert.True(t, IsQueryOption(fmt.Errorf("err: %w", err)))
+	assert.True(t, IsQueryOption(ErrQueryExecution(err)))
+}
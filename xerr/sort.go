@@ -0,0 +1,35 @@
+package xerr
+
+import "errors"
+
+type SortError struct {
+	field  string
+	reason string
+}
+
+func IsSort(err error) bool {
+	return errors.As(err, &SortError{})
+}
+
+func ErrSort(field, reason string) error {
+	return SortError{
+		field:  field,
+		reason: reason,
+	}
+}
+
+func (e SortError) Error() string {
+	if e.field == "" {
+		return "sort: " + e.reason
+	}
+
+	return "sort " + e.field + ": " + e.reason
+}
+
+func (e SortError) Field() string {
+	return e.field
+}
+
+func (e SortError) Reason() string {
+	return e.reason
+}
@@ -0,0 +1,24 @@
+package xerr
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSort(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsSort(nil))
+	assert.False(t, IsSort(sql.ErrNoRows))
+
+	err := ErrSort("name", "unknown field")
+
+	assert.True(t, IsSort(err))
+	assert.True(t, IsSort(fmt.Errorf("err: %w", err)))
+	assert.False(t, IsFilter(err))
+	assert.Equal(t, "sort name: unknown field", err.Error())
+	assert.Equal(t, "sort: empty field", ErrSort("", "empty field").Error())
+}
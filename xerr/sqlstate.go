@@ -0,0 +1,92 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// SQLSTATE codes recognized by Classify.
+const (
+	SQLStateLockNotAvailable = "55P03"
+	SQLStateQueryCanceled    = "57014"
+)
+
+// MySQL error numbers recognized by Classify.
+const (
+	MySQLErrQueryTimeout = 3024 // ER_QUERY_TIMEOUT, the exceeded `MAX_EXECUTION_TIME`
+)
+
+// SQLState returns the SQLSTATE code of the first database error in the err's chain or an empty string if there is none.
+// Errors of github.com/uptrace/bun/driver/pgdriver, github.com/jackc/pgx and github.com/lib/pq are supported.
+func SQLState(err error) string {
+	var withSQLState interface{ SQLState() string } // pgx, pq
+	if errors.As(err, &withSQLState) {
+		return withSQLState.SQLState()
+	}
+
+	var withField interface{ Field(k byte) string } // pgdriver
+	if errors.As(err, &withField) {
+		return withField.Field('C')
+	}
+
+	return ""
+}
+
+// MySQLErrorNumber returns the error number of the first MySQL error in the err's chain or 0 if there is none.
+// Errors of github.com/go-sql-driver/mysql are supported: they are matched by the type name and the Number field,
+// so the driver is not required as a dependency.
+func MySQLErrorNumber(err error) uint16 {
+	if err == nil {
+		return 0
+	}
+
+	if v := reflect.Indirect(reflect.ValueOf(err)); v.Kind() == reflect.Struct && v.Type().Name() == "MySQLError" {
+		if n := v.FieldByName("Number"); n.IsValid() && n.Kind() == reflect.Uint16 {
+			return uint16(n.Uint())
+		}
+	}
+
+	switch err := err.(type) {
+	case interface{ Unwrap() error }:
+		return MySQLErrorNumber(err.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			if n := MySQLErrorNumber(err); n != 0 {
+				return n
+			}
+		}
+	}
+
+	return 0
+}
+
+// Classify wraps the given database error into the corresponding typed error of this package if its kind is recognized,
+// otherwise it returns the error as is.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch SQLState(err) {
+	case SQLStateLockNotAvailable:
+		return ErrLockNotAvailable(err)
+	case SQLStateQueryCanceled:
+		// The same code is used for the cancellation requested by the client on the context cancellation.
+		if !isContextErr(err) {
+			return ErrStatementTimeout(err)
+		}
+
+		return err
+	}
+
+	if MySQLErrorNumber(err) == MySQLErrQueryTimeout {
+		return ErrStatementTimeout(err)
+	}
+
+	return err
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
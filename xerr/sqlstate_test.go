@@ -0,0 +1,88 @@
+package xerr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSQLStateError string
+
+func (e testSQLStateError) Error() string    { return "sqlstate " + string(e) }
+func (e testSQLStateError) SQLState() string { return string(e) }
+
+type testFieldError struct {
+	code string
+}
+
+func (e testFieldError) Error() string { return "field " + e.code }
+
+func (e testFieldError) Field(k byte) string {
+	if k == 'C' {
+		return e.code
+	}
+
+	return ""
+}
+
+func TestSQLState(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, SQLState(nil))
+	assert.Empty(t, SQLState(sql.ErrNoRows))
+	assert.Equal(t, "55P03", SQLState(testSQLStateError("55P03")))
+	assert.Equal(t, "55P03", SQLState(fmt.Errorf("err: %w", testFieldError{code: "55P03"})))
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, Classify(nil))
+
+	err := errors.New("error")
+	assert.Equal(t, err, Classify(err))
+
+	err = testFieldError{code: SQLStateLockNotAvailable}
+	assert.True(t, IsLockNotAvailable(Classify(err)))
+	assert.ErrorIs(t, Classify(err), err)
+}
+
+func TestClassify_statementTimeout(t *testing.T) {
+	t.Parallel()
+
+	var err error = testFieldError{code: SQLStateQueryCanceled}
+	assert.True(t, IsStatementTimeout(Classify(err)))
+	assert.ErrorIs(t, Classify(err), err)
+
+	err = fmt.Errorf("%w: %w", context.Canceled, testFieldError{code: SQLStateQueryCanceled})
+	assert.False(t, IsStatementTimeout(Classify(err)))
+
+	err = fmt.Errorf("err: %w", &MySQLError{Number: MySQLErrQueryTimeout, Message: "Query execution was interrupted"})
+	assert.True(t, IsStatementTimeout(Classify(err)))
+	assert.ErrorIs(t, Classify(err), err)
+
+	// The message is not matched.
+	err = errors.New("Error 3024 (HY000): Query execution was interrupted, maximum statement execution time exceeded")
+	assert.Equal(t, err, Classify(err))
+}
+
+func TestMySQLErrorNumber(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, MySQLErrorNumber(nil))
+	assert.Zero(t, MySQLErrorNumber(sql.ErrNoRows))
+	assert.Equal(t, uint16(1205), MySQLErrorNumber(fmt.Errorf("err: %w", &MySQLError{Number: 1205})))
+	assert.Equal(t, uint16(1205), MySQLErrorNumber(errors.Join(sql.ErrNoRows, &MySQLError{Number: 1205})))
+}
+
+// MySQLError mimics the error of github.com/go-sql-driver/mysql.
+type MySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
@@ -0,0 +1,25 @@
+package xerr
+
+import "errors"
+
+// StatementTimeoutError is returned when a query is aborted by the server for exceeding its statement timeout,
+// e.g. the one set with xbun.StatementTimeout. Unlike the context cancellation, it's not caused by the client.
+type StatementTimeoutError struct {
+	err error
+}
+
+func IsStatementTimeout(err error) bool {
+	return errors.As(err, &StatementTimeoutError{})
+}
+
+func ErrStatementTimeout(err error) StatementTimeoutError {
+	return StatementTimeoutError{err: err}
+}
+
+func (e StatementTimeoutError) Error() string {
+	return "statement timeout: " + e.err.Error()
+}
+
+func (e StatementTimeoutError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,24 @@
+package xerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsStatementTimeout(nil))
+	assert.False(t, IsStatementTimeout(context.DeadlineExceeded))
+
+	err := ErrStatementTimeout(errors.New("error"))
+
+	assert.True(t, IsStatementTimeout(err))
+	assert.True(t, IsStatementTimeout(fmt.Errorf("err: %w", err)))
+	assert.True(t, IsStatementTimeout(ErrQueryExecution(err)))
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+}
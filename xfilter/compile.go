@@ -0,0 +1,143 @@
+package xfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// Compile validates the filter AST against the schema and compiles it to xbun.QueryOption,
+// which adds the filter as a single parenthesized group of WHERE conditions to bun.SelectQuery, bun.UpdateQuery or bun.DeleteQuery.
+func (s Schema) Compile(n Node) (xbun.QueryOption, error) {
+	options, err := s.compileNodes(xbun.SepAND, []Node{n})
+	if err != nil {
+		return nil, err
+	}
+
+	return xbun.WhereGroup(xbun.SepAND, options...), nil
+}
+
+// compileNodes compiles the nodes joined with sep to the options applied within the enclosing group.
+func (s Schema) compileNodes(sep string, nodes []Node) ([]xbun.QueryOption, error) {
+	if sep != xbun.SepAND && sep != xbun.SepOR {
+		return nil, xerr.ErrFilter("", fmt.Sprintf("invalid group separator %q", sep))
+	}
+
+	options := make([]xbun.QueryOption, 0, len(nodes)+1)
+
+	for i, n := range nodes {
+		switch n := n.(type) {
+		case *Group:
+			if i == 0 && n.Not {
+				// bun omits the separator of the first condition within the group, including NOT,
+				// so the neutral condition is added to keep it.
+				options = append(options, neutral(sep))
+			}
+
+			sub, err := s.compileNodes(n.Sep, n.Nodes)
+			if err != nil {
+				return nil, err
+			}
+
+			options = append(options, xbun.WhereGroup(groupSep(sep, n.Not), sub...))
+		case *Condition:
+			opt, err := s.compileCondition(sep, n)
+			if err != nil {
+				return nil, err
+			}
+
+			options = append(options, opt)
+		default:
+			return nil, xerr.ErrFilter("", fmt.Sprintf("unexpected node %T", n))
+		}
+	}
+
+	return options, nil
+}
+
+// compileCondition compiles the condition joined with sep.
+func (s Schema) compileCondition(sep string, c *Condition) (xbun.QueryOption, error) {
+	f, err := s.field(c.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	if !f.allows(c.Op) {
+		return nil, xerr.ErrFilter(c.Field, fmt.Sprintf("operator %s is not allowed", c.Op))
+	}
+
+	if err = validateArity(c.Field, c.Op, len(c.Values)); err != nil {
+		return nil, err
+	}
+
+	expr, args := f.queryExpr()
+
+	where := xbun.Where
+	if sep == xbun.SepOR {
+		where = xbun.WhereOr
+	}
+
+	switch c.Op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpLike:
+		return where(expr+" "+comparisons[c.Op]+" ?", append(args, c.Values[0])...), nil
+	case OpIn:
+		return where(expr+" IN (?)", append(args, bun.In(c.Values))...), nil
+	case OpNin:
+		return where(expr+" NOT IN (?)", append(args, bun.In(c.Values))...), nil
+	case OpBetween:
+		return where(expr+" BETWEEN ? AND ?", append(args, c.Values[0], c.Values[1])...), nil
+	case OpNull:
+		if isNull, ok := c.Values[0].(bool); !ok || isNull {
+			return where(expr+" IS NULL", args...), nil
+		}
+
+		return where(expr+" IS NOT NULL", args...), nil
+	case OpILike:
+		pattern, ok := c.Values[0].(string)
+		if !ok {
+			return nil, xerr.ErrFilter(c.Field, fmt.Sprintf("unexpected %T value of operator %s", c.Values[0], c.Op))
+		}
+
+		opt := xbun.WhereILikeExpr(expr, args, pattern, xbun.LikePattern)
+		if sep == xbun.SepOR {
+			opt = xbun.WhereGroup(xbun.SepOR, opt)
+		}
+
+		return opt, nil
+	default:
+		return nil, xerr.ErrFilter(c.Field, fmt.Sprintf("unknown operator %q", c.Op))
+	}
+}
+
+// comparisons maps the single-value operators to SQL.
+var comparisons = map[Op]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLike: "LIKE",
+}
+
+// groupSep returns the separator of the group within the enclosing one joined with sep.
+func groupSep(sep string, not bool) string {
+	if !not {
+		return sep
+	}
+
+	return strings.TrimSuffix(sep, " ") + xbun.SepNOT
+}
+
+// neutral returns the condition not affecting the result of the group joined with sep.
+func neutral(sep string) xbun.QueryOption {
+	if sep == xbun.SepOR {
+		return xbun.Where("1 = 0")
+	}
+
+	return xbun.Where("1 = 1")
+}
@@ -0,0 +1,81 @@
+package xfilter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestSchema_Compile(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	values, err := url.ParseQuery("status=in:active,pending&age=between:18,65&deleted_at=null:false&email=like:%25@x&score=ne:1.5")
+	require.NoError(t, err)
+
+	group, err := testSchema.ParseQuery(values)
+	require.NoError(t, err)
+
+	opt, err := testSchema.Compile(group)
+	require.NoError(t, err)
+
+	q := xbun.QueryOptions(testSelect(db).Where("?TableAlias.id > 0"), opt)
+	require.Equal(t, `SELECT "u"."id" FROM "users" AS "u" WHERE ("u".id > 0) AND ((`+
+		`("u"."age" BETWEEN 18 AND 65) AND ("u"."deleted_at" IS NOT NULL) AND (lower("u".email) LIKE '%@x') `+
+		`AND ("u"."rating" <> 1.5) AND ("u"."status" IN ('active', 'pending'))))`, q.String())
+}
+
+func TestSchema_Compile_groups(t *testing.T) {
+	t.Parallel()
+
+	db := testDB()
+
+	group, err := testSchema.ParseJSON([]byte(`{"not": {"status": "x"}, "or": [{"age": {"lt": 18}}, {"status": {"ilike": "a%"}}]}`))
+	require.NoError(t, err)
+
+	opt, err := testSchema.Compile(group)
+	require.NoError(t, err)
+
+	q := xbun.QueryOptions(testSelect(db), opt)
+	require.Equal(t, `SELECT "u"."id" FROM "users" AS "u" WHERE (((1 = 1) AND NOT (("u"."status" = 'x')) AND `+
+		`((("u"."age" < 18)) OR (("u"."status" ILIKE 'a%' ESCAPE '\')))))`, q.String())
+
+	opt, err = testSchema.Compile(&Group{Sep: xbun.SepOR, Not: true, Nodes: []Node{
+		&Group{Sep: xbun.SepAND, Not: true, Nodes: []Node{&Condition{Field: "age", Op: OpEq, Values: []any{int64(1)}}}},
+		&Condition{Field: "age", Op: OpEq, Values: []any{int64(2)}},
+	}})
+	require.NoError(t, err)
+
+	q = xbun.QueryOptions(testSelect(db), opt)
+	require.Equal(t, `SELECT "u"."id" FROM "users" AS "u" WHERE ((1 = 1) AND NOT ((1 = 0) OR NOT (("u"."age" = 1)) `+
+		`OR ("u"."age" = 2)))`, q.String())
+}
+
+func TestSchema_Compile_error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		node Node
+	}{
+		{"unknown field", &Condition{Field: "unknown", Op: OpEq, Values: []any{1}}},
+		{"not allowed", &Condition{Field: "email", Op: OpIn, Values: []any{"a"}}},
+		{"arity", &Condition{Field: "age", Op: OpEq}},
+		{"separator", &Group{Sep: xbun.SepNOT}},
+		{"node", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testSchema.Compile(tt.node)
+			require.True(t, xerr.IsFilter(err))
+		})
+	}
+}
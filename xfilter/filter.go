@@ -0,0 +1,75 @@
+package xfilter
+
+import (
+	"fmt"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// Op is a filter condition operator.
+type Op string
+
+const (
+	OpEq      Op = "eq"
+	OpNe      Op = "ne"
+	OpLt      Op = "lt"
+	OpLte     Op = "lte"
+	OpGt      Op = "gt"
+	OpGte     Op = "gte"
+	OpIn      Op = "in"
+	OpNin     Op = "nin"
+	OpLike    Op = "like"
+	OpILike   Op = "ilike"
+	OpNull    Op = "null"
+	OpBetween Op = "between"
+)
+
+// ops lists all the known operators.
+var ops = []Op{OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpIn, OpNin, OpLike, OpILike, OpNull, OpBetween}
+
+type (
+	// Node is a node of the filter AST: either *Group or *Condition.
+	Node interface {
+		node()
+	}
+
+	// Group is a parenthesized group of nodes joined with Sep, which is either xbun.SepAND or xbun.SepOR.
+	// If Not is set, the whole group is negated.
+	Group struct {
+		Sep   string
+		Not   bool
+		Nodes []Node
+	}
+
+	// Condition is a single filter condition on the field.
+	// Values are already coerced to the field Type: there is exactly one value for most operators,
+	// at least one for OpIn and OpNin, two for OpBetween and a single bool for OpNull (true means IS NULL).
+	Condition struct {
+		Field  string
+		Op     Op
+		Values []any
+	}
+)
+
+func (*Group) node()     {}
+func (*Condition) node() {}
+
+// validateArity checks the number of the condition values for the given operator.
+func validateArity(field string, op Op, n int) error {
+	switch op {
+	case OpIn, OpNin:
+		if n == 0 {
+			return xerr.ErrFilter(field, fmt.Sprintf("operator %s requires at least one value", op))
+		}
+	case OpBetween:
+		if n != 2 {
+			return xerr.ErrFilter(field, fmt.Sprintf("operator %s requires exactly two values", op))
+		}
+	default:
+		if n != 1 {
+			return xerr.ErrFilter(field, fmt.Sprintf("operator %s requires exactly one value", op))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package xfilter
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+type testUser struct {
+	bun.BaseModel `bun:"table:users,alias:u"`
+
+	ID        int64        `bun:"id,pk"`
+	Status    string       `bun:"status"`
+	Age       int64        `bun:"age"`
+	Active    bool         `bun:"active"`
+	CreatedAt time.Time    `bun:"created_at"`
+	DeletedAt bun.NullTime `bun:"deleted_at"`
+}
+
+var testSchema = Schema{
+	"status":     {Type: TypeString},
+	"email":      {Expr: "lower(?TableAlias.email)", Type: TypeString, Ops: []Op{OpEq, OpLike}},
+	"age":        {Type: TypeInt},
+	"score":      {Column: "rating", Type: TypeFloat},
+	"active":     {Type: TypeBool},
+	"created_at": {Type: TypeTime},
+	"deleted_at": {Type: TypeTime, Ops: []Op{OpNull}},
+}
+
+func testDB() *bun.DB {
+	return bun.NewDB(&sql.DB{}, pgdialect.New())
+}
+
+func testSelect(db *bun.DB) *bun.SelectQuery {
+	return db.NewSelect().Model((*testUser)(nil)).Column("id")
+}
@@ -0,0 +1,191 @@
+package xfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// JSON filter group keys.
+const (
+	jsonAnd = "and"
+	jsonOr  = "or"
+	jsonNot = "not"
+)
+
+// ParseJSON parses the JSON filter, which is the JSON equivalent of ParseQuery extended with logical groups.
+//
+// The filter is an object, which keys are joined with AND. Each key is either:
+// - a field name with a scalar value meaning OpEq, or an object of operators to values, e.g. `{"status": {"in": ["a", "b"]}}`;
+// - "and" or "or" with an array of nested filter objects;
+// - "not" with a nested filter object to negate.
+//
+// OpIn and OpNin require array values, OpBetween requires a two-element array, OpNull accepts a bool.
+func (s Schema) ParseJSON(data []byte) (*Group, error) {
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseJSONObject(obj)
+}
+
+func (s Schema) parseJSONObject(obj map[string]json.RawMessage) (*Group, error) {
+	group := &Group{Sep: xbun.SepAND}
+
+	for _, key := range sortedKeys(obj) {
+		nodes, err := s.parseJSONKey(key, obj[key])
+		if err != nil {
+			return nil, err
+		}
+
+		group.Nodes = append(group.Nodes, nodes...)
+	}
+
+	return group, nil
+}
+
+func (s Schema) parseJSONKey(key string, data json.RawMessage) ([]Node, error) {
+	switch key {
+	case jsonAnd, jsonOr:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, xerr.ErrFilter("", fmt.Sprintf("%q requires an array of filters", key))
+		}
+
+		group := &Group{Sep: xbun.SepAND}
+		if key == jsonOr {
+			group.Sep = xbun.SepOR
+		}
+
+		for _, item := range items {
+			obj, err := decodeObject(item)
+			if err != nil {
+				return nil, err
+			}
+
+			sub, err := s.parseJSONObject(obj)
+			if err != nil {
+				return nil, err
+			}
+
+			group.Nodes = append(group.Nodes, sub)
+		}
+
+		return []Node{group}, nil
+	case jsonNot:
+		obj, err := decodeObject(data)
+		if err != nil {
+			return nil, err
+		}
+
+		group, err := s.parseJSONObject(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		group.Not = true
+
+		return []Node{group}, nil
+	default:
+		return s.parseJSONField(key, data)
+	}
+}
+
+func (s Schema) parseJSONField(name string, data json.RawMessage) ([]Node, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		raw, err := decodeValues(name, OpEq, data)
+		if err != nil {
+			return nil, err
+		}
+
+		cond, err := s.condition(name, OpEq, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Node{cond}, nil
+	}
+
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]Node, 0, len(obj))
+
+	for _, key := range sortedKeys(obj) {
+		op := Op(key)
+		if !slices.Contains(ops, op) {
+			return nil, xerr.ErrFilter(name, fmt.Sprintf("unknown operator %q", key))
+		}
+
+		raw, err := decodeValues(name, op, obj[key])
+		if err != nil {
+			return nil, err
+		}
+
+		cond, err := s.condition(name, op, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, cond)
+	}
+
+	return nodes, nil
+}
+
+// decodeValues decodes the raw operator values, which are arrays for the multi-value operators and scalars otherwise.
+func decodeValues(name string, op Op, data json.RawMessage) ([]any, error) {
+	var v any
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, xerr.ErrFilter(name, "invalid JSON value")
+	}
+
+	arr, isArr := v.([]any)
+
+	switch op {
+	case OpIn, OpNin, OpBetween:
+		if !isArr {
+			return nil, xerr.ErrFilter(name, fmt.Sprintf("operator %s requires an array", op))
+		}
+
+		return arr, nil
+	default:
+		if isArr || v == nil {
+			return nil, xerr.ErrFilter(name, fmt.Sprintf("operator %s requires a scalar", op))
+		}
+
+		return []any{v}, nil
+	}
+}
+
+func decodeObject(data []byte) (map[string]json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return nil, xerr.ErrFilter("", "filter must be a JSON object")
+	}
+
+	return obj, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,69 @@
+package xfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestSchema_ParseJSON(t *testing.T) {
+	t.Parallel()
+
+	group, err := testSchema.ParseJSON([]byte(`{
+		"status": "active",
+		"age": {"gte": 18, "lt": "65"},
+		"or": [{"score": {"gt": 4.5}}, {"active": true}],
+		"not": {"status": {"in": ["banned"]}},
+		"deleted_at": {"null": false}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, &Group{Sep: xbun.SepAND, Nodes: []Node{
+		&Condition{Field: "age", Op: OpGte, Values: []any{int64(18)}},
+		&Condition{Field: "age", Op: OpLt, Values: []any{int64(65)}},
+		&Condition{Field: "deleted_at", Op: OpNull, Values: []any{false}},
+		&Group{Sep: xbun.SepAND, Not: true, Nodes: []Node{
+			&Condition{Field: "status", Op: OpIn, Values: []any{"banned"}},
+		}},
+		&Group{Sep: xbun.SepOR, Nodes: []Node{
+			&Group{Sep: xbun.SepAND, Nodes: []Node{&Condition{Field: "score", Op: OpGt, Values: []any{4.5}}}},
+			&Group{Sep: xbun.SepAND, Nodes: []Node{&Condition{Field: "active", Op: OpEq, Values: []any{true}}}},
+		}},
+		&Condition{Field: "status", Op: OpEq, Values: []any{"active"}},
+	}}, group)
+}
+
+func TestSchema_ParseJSON_error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		json  string
+		field string
+	}{
+		{`[]`, ""},
+		{`{"or": {}}`, ""},
+		{`{"not": []}`, ""},
+		{`{"unknown": 1}`, "unknown"},
+		{`{"age": {"eq": 1.5}}`, "age"},
+		{`{"age": {"foo": 1}}`, "age"},
+		{`{"age": {"in": 1}}`, "age"},
+		{`{"age": {"eq": [1]}}`, "age"},
+		{`{"age": null}`, "age"},
+		{`{"status": true}`, "status"},
+		{`{"or": [{"age": "x"}]}`, "age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := testSchema.ParseJSON([]byte(tt.json))
+
+			fErr := xerr.FilterError{}
+			require.ErrorAs(t, err, &fErr)
+			require.Equal(t, tt.field, fErr.Field())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package xfilter
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/heffcodex/xbun"
+)
+
+// ParseQuery parses the URL query filters like `status=in:active,pending&created_at=gte:2024-01-01` into the AND group of conditions.
+//
+// Each value is `[op:]value`, where op is one of Op constants defaulting to OpEq if it's omitted or unknown
+// (so the values containing colons like times are still allowed for OpEq).
+// Values of OpIn, OpNin and OpBetween are comma-separated, OpNull accepts an optional bool value defaulting to true.
+// Repeated keys produce multiple conditions, e.g. `age=gte:18&age=lt:65`.
+//
+// All the keys must be the fields of the schema, so the non-filter parameters (e.g. pagination) must be removed beforehand.
+func (s Schema) ParseQuery(values url.Values) (*Group, error) {
+	group := &Group{Sep: xbun.SepAND}
+
+	for _, key := range sortedKeys(values) {
+		for _, value := range values[key] {
+			op, raw := splitQueryValue(value)
+
+			cond, err := s.condition(key, op, raw)
+			if err != nil {
+				return nil, err
+			}
+
+			group.Nodes = append(group.Nodes, cond)
+		}
+	}
+
+	return group, nil
+}
+
+// splitQueryValue splits the query value into the operator and its raw values.
+func splitQueryValue(value string) (Op, []any) {
+	op := OpEq
+
+	if prefix, rest, ok := strings.Cut(value, ":"); ok && slices.Contains(ops, Op(prefix)) {
+		op, value = Op(prefix), rest
+	} else if value == string(OpNull) {
+		return OpNull, nil
+	}
+
+	switch op {
+	case OpIn, OpNin, OpBetween:
+		if value == "" {
+			return op, nil
+		}
+
+		parts := strings.Split(value, ",")
+		raw := make([]any, len(parts))
+
+		for i, part := range parts {
+			raw[i] = part
+		}
+
+		return op, raw
+	case OpNull:
+		if value == "" {
+			return op, nil
+		}
+	}
+
+	return op, []any{value}
+}
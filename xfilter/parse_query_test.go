@@ -0,0 +1,95 @@
+package xfilter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+func TestSchema_ParseQuery(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery("status=in:active,pending&created_at=gte:2024-01-01&age=between:18,65&age=ne:30" +
+		"&deleted_at=null&active=true&score=lt:4.5&email=like:%25@example.com")
+	require.NoError(t, err)
+
+	group, err := testSchema.ParseQuery(values)
+	require.NoError(t, err)
+	require.Equal(t, &Group{Sep: xbun.SepAND, Nodes: []Node{
+		&Condition{Field: "active", Op: OpEq, Values: []any{true}},
+		&Condition{Field: "age", Op: OpBetween, Values: []any{int64(18), int64(65)}},
+		&Condition{Field: "age", Op: OpNe, Values: []any{int64(30)}},
+		&Condition{Field: "created_at", Op: OpGte, Values: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		&Condition{Field: "deleted_at", Op: OpNull, Values: []any{true}},
+		&Condition{Field: "email", Op: OpLike, Values: []any{"%@example.com"}},
+		&Condition{Field: "score", Op: OpLt, Values: []any{4.5}},
+		&Condition{Field: "status", Op: OpIn, Values: []any{"active", "pending"}},
+	}}, group)
+}
+
+func TestSchema_ParseQuery_value(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		cond  *Condition
+	}{
+		{"status=a:b", &Condition{Field: "status", Op: OpEq, Values: []any{"a:b"}}},
+		{"status=eq:in:x", &Condition{Field: "status", Op: OpEq, Values: []any{"in:x"}}},
+		{"created_at=2024-01-01T10:00:00Z", &Condition{Field: "created_at", Op: OpEq, Values: []any{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}}},
+		{"deleted_at=null:false", &Condition{Field: "deleted_at", Op: OpNull, Values: []any{false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			group, err := testSchema.ParseQuery(values)
+			require.NoError(t, err)
+			require.Equal(t, []Node{tt.cond}, group.Nodes)
+		})
+	}
+}
+
+func TestSchema_ParseQuery_error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"unknown=1", "unknown"},
+		{"age=abc", "age"},
+		{"age=in:", "age"},
+		{"age=between:1", "age"},
+		{"age=like:1", "age"},
+		{"active=gt:true", "active"},
+		{"email=ne:x", "email"},
+		{"deleted_at=2024-01-01", "deleted_at"},
+		{"deleted_at=null:maybe", "deleted_at"},
+		{"created_at=yesterday", "created_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			_, err = testSchema.ParseQuery(values)
+
+			fErr := xerr.FilterError{}
+			require.ErrorAs(t, err, &fErr)
+			require.Equal(t, tt.field, fErr.Field())
+		})
+	}
+}
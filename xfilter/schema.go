@@ -0,0 +1,192 @@
+package xfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// Type is the type the filter values are coerced to.
+type Type uint8
+
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeTime
+)
+
+// dateLayout is accepted for TypeTime values along with time.RFC3339Nano.
+const dateLayout = time.DateOnly
+
+// Field describes a filterable field.
+type Field struct {
+	// Column is the column name qualified with the query's table alias.
+	// By default, it's the name of the field in Schema.
+	Column string
+
+	// Expr is a raw SQL expression used instead of Column, e.g. `lower(?TableAlias.email)`.
+	// It's not escaped in any way, so it must never come from the user input.
+	Expr string
+
+	// Type is the type the values are coerced to.
+	Type Type
+
+	// Ops is the whitelist of the allowed operators. By default, all the operators applicable to Type are allowed.
+	Ops []Op
+}
+
+// Schema is the whitelist of filterable fields by their public names.
+type Schema map[string]Field
+
+// field returns the schema field by its name.
+func (s Schema) field(name string) (Field, error) {
+	f, ok := s[name]
+	if !ok {
+		return Field{}, xerr.ErrFilter(name, "unknown field")
+	}
+
+	if f.Column == "" && f.Expr == "" {
+		f.Column = name
+	}
+
+	return f, nil
+}
+
+// condition validates the operator and coerces the raw values to the field type.
+func (s Schema) condition(name string, op Op, raw []any) (*Condition, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !f.allows(op) {
+		return nil, xerr.ErrFilter(name, fmt.Sprintf("operator %s is not allowed", op))
+	}
+
+	if op == OpNull {
+		if len(raw) == 0 {
+			raw = []any{true}
+		}
+
+		return coerceCondition(name, op, TypeBool, raw)
+	}
+
+	return coerceCondition(name, op, f.Type, raw)
+}
+
+func coerceCondition(name string, op Op, typ Type, raw []any) (*Condition, error) {
+	if err := validateArity(name, op, len(raw)); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(raw))
+
+	for i, r := range raw {
+		v, err := coerce(typ, r)
+		if err != nil {
+			return nil, xerr.ErrFilter(name, err.Error())
+		}
+
+		values[i] = v
+	}
+
+	return &Condition{Field: name, Op: op, Values: values}, nil
+}
+
+// allows checks if the operator is allowed for the field.
+func (f Field) allows(op Op) bool {
+	if f.Ops != nil && !slices.Contains(f.Ops, op) {
+		return false
+	}
+
+	switch op {
+	case OpLike, OpILike:
+		return f.Type == TypeString
+	case OpLt, OpLte, OpGt, OpGte, OpBetween:
+		return f.Type != TypeBool
+	case OpEq, OpNe, OpIn, OpNin, OpNull:
+		return true
+	default:
+		return false
+	}
+}
+
+// queryExpr returns the field expression and its args suitable for bun queries.
+func (f Field) queryExpr() (string, []any) {
+	if f.Expr != "" {
+		return f.Expr, nil
+	}
+
+	return "?TableAlias.?", []any{bun.Ident(f.Column)}
+}
+
+// coerce converts the raw value, which is either a string from the query string or a JSON scalar, to the given type.
+func coerce(typ Type, raw any) (any, error) {
+	switch raw := raw.(type) {
+	case string:
+		return coerceString(typ, raw)
+	case json.Number:
+		if typ == TypeString {
+			return raw.String(), nil
+		}
+
+		return coerceString(typ, raw.String())
+	case bool:
+		if typ != TypeBool {
+			return nil, fmt.Errorf("unexpected bool value %t", raw)
+		}
+
+		return raw, nil
+	default:
+		return nil, fmt.Errorf("unexpected value %v", raw)
+	}
+}
+
+func coerceString(typ Type, s string) (any, error) {
+	switch typ {
+	case TypeString:
+		return s, nil
+	case TypeInt:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+
+		return v, nil
+	case TypeFloat:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+
+		return v, nil
+	case TypeBool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", s)
+		}
+
+		return v, nil
+	case TypeTime:
+		if v, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return v, nil
+		}
+
+		v, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", s)
+		}
+
+		return v, nil
+	default:
+		return nil, fmt.Errorf("invalid type %d", typ)
+	}
+}
@@ -0,0 +1,269 @@
+// Package xlog provides bun.QueryHook logging the queries with log/slog.
+package xlog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+var _ bun.QueryHook = (*Hook)(nil)
+
+// DefaultExplainTimeout is the default Hook.ExplainTimeout.
+const DefaultExplainTimeout = time.Second
+
+// Hook is bun.QueryHook logging every executed query with its duration, rows affected, error class, caller location and model table.
+//
+// Failed queries are logged at slog.LevelError, the ones slower than SlowThreshold at slog.LevelWarn and the rest at Level.
+// Only the latter are subject to sampling.
+//
+// Values of the string or bytes model fields tagged with `xbun:"redact"` and the arguments wrapped with Redact
+// are replaced with RedactedValue in the logged query, which is rendered again for that (see redactedQuery).
+// EXPLAIN is run for the logged query, so its output doesn't contain the redacted values either.
+type Hook struct {
+	// Logger is the logger to use, slog.Default() by default.
+	Logger *slog.Logger
+
+	// Level is the level of the successful queries, slog.LevelInfo by default.
+	Level slog.Level
+
+	// SlowThreshold is the duration starting from which the query is considered slow. Zero disables slow query detection.
+	SlowThreshold time.Duration
+
+	// Explain enables attaching the EXPLAIN output to the slow queries.
+	// It's run outside the query transaction, so the queries depending on its uncommitted state may fail to explain.
+	Explain bool
+
+	// ExplainTimeout limits the EXPLAIN execution, which blocks the query caller, DefaultExplainTimeout by default.
+	// It applies regardless of the query context cancellation, since the query context could be done already.
+	ExplainTimeout time.Duration
+
+	// Sample decides if the successful query below SlowThreshold is logged. By default, all of them are.
+	Sample Sampler
+}
+
+// BeforeQuery implements bun.QueryHook.
+func (h *Hook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery implements bun.QueryHook.
+func (h *Hook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	duration := time.Since(event.StartTime)
+	failed := event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows)
+	slow := h.SlowThreshold > 0 && duration >= h.SlowThreshold
+
+	level := h.Level
+	switch {
+	case failed:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	case h.Sample != nil && !h.Sample(event):
+		return
+	}
+
+	logger := h.logger()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	query := redactedQuery(event)
+
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.String("operation", event.Operation()),
+		slog.Duration("duration", duration),
+	}
+
+	if table := tableName(event); table != "" {
+		attrs = append(attrs, slog.String("table", table))
+	}
+
+	if event.Result != nil {
+		if n, err := event.Result.RowsAffected(); err == nil {
+			attrs = append(attrs, slog.Int64("rows_affected", n))
+		}
+	}
+
+	if event.Err != nil {
+		attrs = append(attrs, slog.String("error", event.Err.Error()), slog.String("error_class", ErrorClass(event.Err)))
+	}
+
+	if caller := callerLocation(); caller != "" {
+		attrs = append(attrs, slog.String("caller", caller))
+	}
+
+	if slow && h.Explain {
+		plan, err := explain(ctx, event, query, h.explainTimeout())
+		if err != nil {
+			attrs = append(attrs, slog.String("explain_error", err.Error()))
+		} else {
+			attrs = append(attrs, slog.String("explain", plan))
+		}
+	}
+
+	msg := "xbun: query"
+	if slow {
+		msg = "xbun: slow query"
+	}
+
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (h *Hook) explainTimeout() time.Duration {
+	if h.ExplainTimeout > 0 {
+		return h.ExplainTimeout
+	}
+
+	return DefaultExplainTimeout
+}
+
+func (h *Hook) logger() *slog.Logger {
+	if h.Logger != nil {
+		return h.Logger
+	}
+
+	return slog.Default()
+}
+
+// ErrorClass returns the short name of the error kind recognized by xerr.Classify, e.g. "lock_not_available".
+// It returns "no_rows" for sql.ErrNoRows and "other" for the unrecognized errors.
+func ErrorClass(err error) string {
+	err = xerr.Classify(err)
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, sql.ErrNoRows):
+		return "no_rows"
+	case xerr.IsStatementTimeout(err):
+		return "statement_timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case xerr.IsLockNotAvailable(err):
+		return "lock_not_available"
+	case xerr.IsAffectedRows(err):
+		return "affected_rows"
+	case xerr.IsQueryOption(err):
+		return "query_option"
+	default:
+		return "other"
+	}
+}
+
+// tableName returns the name of the query model table if there is one.
+func tableName(event *bun.QueryEvent) string {
+	if event.IQuery == nil {
+		return ""
+	}
+
+	return event.IQuery.GetTableName()
+}
+
+// callerLocation returns the `file:line` location of the first caller outside of bun and this module (except its tests).
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+var internalPackages = []string{
+	"github.com/uptrace/bun.",
+	"github.com/uptrace/bun/",
+	"github.com/heffcodex/xbun.",
+	"github.com/heffcodex/xbun/",
+	"database/sql.",
+	"runtime.",
+}
+
+func isInternalFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	for _, pkg := range internalPackages {
+		if strings.HasPrefix(frame.Function, pkg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// explain returns the EXPLAIN output of the query of the event with the rows separated by newlines and the columns by ` | `.
+// The given query is explained instead of the event one, so the redacted values don't leak to the output.
+// It's run with the values of the query context, but not its cancellation, limited by the given timeout.
+func explain(ctx context.Context, event *bun.QueryEvent, query string, timeout time.Duration) (string, error) {
+	switch event.Operation() {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+	default:
+		return "", errors.New("unsupported operation")
+	}
+
+	prefix := "EXPLAIN "
+	if event.DB.Dialect().Name() == dialect.SQLite {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	rows, err := event.DB.DB.QueryContext(ctx, prefix+query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		lines  []string
+		values = make([]sql.NullString, len(columns))
+		dest   = make([]any, len(columns))
+	)
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return "", err
+		}
+
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = v.String
+		}
+
+		lines = append(lines, strings.Join(cells, " | "))
+	}
+
+	return strings.Join(lines, "\n"), rows.Err()
+}
@@ -0,0 +1,73 @@
+package xlog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// blockingConnector is driver.Connector which connections block every query until its context is done.
+type blockingConnector struct{}
+
+func (blockingConnector) Connect(context.Context) (driver.Conn, error) { return blockingConn{}, nil }
+func (blockingConnector) Driver() driver.Driver                        { return nil }
+
+type blockingConn struct{}
+
+func (blockingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.ErrUnsupported }
+func (blockingConn) Close() error                        { return nil }
+func (blockingConn) Begin() (driver.Tx, error)           { return nil, errors.ErrUnsupported }
+
+func (blockingConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestExplain_timeout(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(sql.OpenDB(blockingConnector{}), pgdialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := explain(ctx, &bun.QueryEvent{DB: db, Query: "SELECT 1"}, "SELECT 1", time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestErrorClass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{sql.ErrNoRows, "no_rows"},
+		{fmt.Errorf("wrapped: %w", context.Canceled), "canceled"},
+		{context.DeadlineExceeded, "deadline_exceeded"},
+		{xerr.ErrStatementTimeout(errors.New("timeout")), "statement_timeout"},
+		{xerr.ErrLockNotAvailable(errors.New("lock")), "lock_not_available"},
+		{xerr.ErrAffectedRows(1, 0, xerr.AffectedExactly), "affected_rows"},
+		{xerr.ErrQueryOption("Limit", "unsupported"), "query_option"},
+		{errors.New("unknown"), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, ErrorClass(tt.err))
+		})
+	}
+}
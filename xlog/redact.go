@@ -0,0 +1,262 @@
+package xlog
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// RedactedValue replaces the values of the redacted fields and arguments in the logs.
+const RedactedValue = "[REDACTED]"
+
+// redactTag is the value of the `xbun` struct tag marking the field as redacted.
+const redactTag = "redact"
+
+// redactMarker replaces the values of the redacted fields in the model copy the logged query is rendered with,
+// so the literals of the fields could be told apart from the other ones in the query.
+const redactMarker = "xbun:redact:6f1d0c9a2e8b4735"
+
+// redactedFields caches the redacted fields per table.
+var redactedFields sync.Map // map[*schema.Table][]*schema.Field
+
+// tableModel is implemented by bun's table models.
+type tableModel interface {
+	bun.Model
+	Table() *schema.Table
+}
+
+var _ schema.QueryAppender = redactedArg{}
+
+// Redact wraps the query argument, so it's logged by Hook as RedactedValue, e.g. `q.Where("token = ?", xlog.Redact(token))`.
+// The executed query has the argument as is.
+func Redact(value any) schema.QueryAppender {
+	return redactedArg{value: value}
+}
+
+type redactedArg struct {
+	value any
+}
+
+func (a redactedArg) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if _, ok := fmter.Dialect().(redactingDialect); ok {
+		return fmter.Dialect().AppendString(b, RedactedValue), nil
+	}
+
+	return schema.Append(fmter, b, a.value), nil
+}
+
+// redactingDialect is the dialect of the formatter rendering the logged query, which makes Redact arguments redacted.
+type redactingDialect struct {
+	schema.Dialect
+}
+
+// redactedQuery returns the query of the event rendered again with Redact arguments and the values of the model fields
+// tagged with `xbun:"redact"` replaced by RedactedValue.
+// The field values are redacted in INSERT, UPDATE and DELETE queries only, since the other ones don't contain them usually.
+// If the query can't be rendered or its redacted fields are not strings or bytes, RedactedValue is returned instead of the query.
+func redactedQuery(event *bun.QueryEvent) string {
+	if event.IQuery == nil || event.DB == nil {
+		return event.Query
+	}
+
+	var (
+		query    = event.IQuery
+		fmter    = schema.NewFormatter(redactingDialect{Dialect: event.DB.Dialect()})
+		literals []string
+	)
+
+	if model, ok := event.Model.(tableModel); ok {
+		if fields := tableRedactedFields(model.Table()); len(fields) > 0 {
+			var redacted bool
+			if query, literals, redacted = withRedactedModel(query, model, fields, fmter); !redacted {
+				return RedactedValue
+			}
+		}
+	}
+
+	b, err := query.AppendQuery(fmter, nil)
+	if err != nil {
+		return RedactedValue
+	}
+
+	s := string(b)
+	redacted := string(fmter.Dialect().AppendString(nil, RedactedValue))
+
+	for _, literal := range literals {
+		s = strings.ReplaceAll(s, literal, redacted)
+	}
+
+	return s
+}
+
+// withRedactedModel returns the copy of the query with the copy of the model which redacted fields are set to redactMarker,
+// and the literals of the marker in the query. It reports false if the fields can't be redacted.
+// Queries other than INSERT, UPDATE and DELETE are returned as is.
+func withRedactedModel(q bun.Query, model tableModel, fields []*schema.Field, fmter schema.Formatter) (bun.Query, []string, bool) {
+	var withModel func(model any) bun.Query
+
+	switch q := q.(type) {
+	case *bun.InsertQuery:
+		withModel = func(model any) bun.Query { cp := *q; return cp.Model(model) }
+	case *bun.UpdateQuery:
+		withModel = func(model any) bun.Query { cp := *q; return cp.Model(model) }
+	case *bun.DeleteQuery:
+		withModel = func(model any) bun.Query { cp := *q; return cp.Model(model) }
+	default:
+		return q, nil, true
+	}
+
+	value, ok := copyModel(reflect.ValueOf(model.Value()))
+	if !ok {
+		return nil, nil, false
+	}
+
+	var literals []string
+
+	eachStruct(value, func(strct reflect.Value) {
+		for _, f := range fields {
+			if !ok {
+				return
+			}
+
+			var marked bool
+			if marked, ok = setRedactMarker(strct, f); !ok {
+				return
+			}
+
+			if literal := string(f.AppendValue(fmter, nil, strct)); marked && !slices.Contains(literals, literal) {
+				literals = append(literals, literal)
+			}
+		}
+	})
+
+	if !ok {
+		return nil, nil, false
+	}
+
+	return withModel(value.Interface()), literals, true
+}
+
+// copyModel returns the pointer to the copy of the struct or the slice of structs (or pointers to them) the value points to.
+// Only the structs are copied, so the values they point to are shared.
+func copyModel(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return reflect.Value{}, false
+	}
+
+	elem := v.Elem()
+	cp := reflect.New(elem.Type())
+
+	switch elem.Kind() {
+	case reflect.Struct:
+		cp.Elem().Set(elem)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(elem.Type(), elem.Len(), elem.Len())
+
+		for i := range elem.Len() {
+			item := elem.Index(i)
+			if item.Kind() == reflect.Pointer && !item.IsNil() {
+				ptr := reflect.New(item.Type().Elem())
+				ptr.Elem().Set(item.Elem())
+				item = ptr
+			}
+
+			slice.Index(i).Set(item)
+		}
+
+		cp.Elem().Set(slice)
+	default:
+		return reflect.Value{}, false
+	}
+
+	return cp, true
+}
+
+// setRedactMarker sets the field of the struct copy to redactMarker unless it's NULL and reports whether it's set.
+// The pointer fields are set to the new pointers, so the original values are not modified.
+// It reports false as ok if the field is not a string or bytes or it belongs to the struct embedded by pointer,
+// which is shared with the original.
+func setRedactMarker(strct reflect.Value, f *schema.Field) (marked, ok bool) {
+	fv := strct
+
+	for i, index := range f.Index {
+		if i > 0 && fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				return false, true // the field is NULL
+			}
+
+			return false, false
+		}
+
+		fv = fv.Field(index)
+	}
+
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return false, true
+		}
+
+		ptr := reflect.New(fv.Type().Elem())
+		fv.Set(ptr)
+		fv = ptr.Elem()
+	}
+
+	switch {
+	case fv.Kind() == reflect.String:
+		fv.SetString(redactMarker)
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		if fv.IsNil() {
+			return false, true
+		}
+
+		fv.SetBytes([]byte(redactMarker))
+	default:
+		return false, false
+	}
+
+	return true, true
+}
+
+// tableRedactedFields returns the fields of the table tagged with `xbun:"redact"`.
+func tableRedactedFields(table *schema.Table) []*schema.Field {
+	if cached, ok := redactedFields.Load(table); ok {
+		return cached.([]*schema.Field)
+	}
+
+	var fields []*schema.Field
+
+	for _, f := range table.Fields {
+		if slices.Contains(strings.Split(f.StructField.Tag.Get("xbun"), ","), redactTag) {
+			fields = append(fields, f)
+		}
+	}
+
+	redactedFields.Store(table, fields)
+
+	return fields
+}
+
+// eachStruct calls fn for the struct the value points to or for every struct element of the slice it points to.
+func eachStruct(v reflect.Value, fn func(strct reflect.Value)) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fn(v)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			eachStruct(v.Index(i), fn)
+		}
+	default:
+	}
+}
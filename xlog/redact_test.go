@@ -0,0 +1,80 @@
+package xlog
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+type testSecret struct {
+	bun.BaseModel `bun:"table:secrets,alias:s"`
+
+	ID    int64   `bun:"id,pk"`
+	Name  string  `bun:"name"`
+	Value string  `bun:"value" xbun:"redact"`
+	Note  *string `bun:"note" xbun:"redact"`
+}
+
+type testSecretCounter struct {
+	bun.BaseModel `bun:"table:secrets,alias:s"`
+
+	ID    int64 `bun:"id,pk"`
+	Value int64 `bun:"value" xbun:"redact"`
+}
+
+func queryEvent(db *bun.DB, q bun.Query) *bun.QueryEvent {
+	b, err := q.AppendQuery(db.Formatter(), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return &bun.QueryEvent{DB: db, IQuery: q, Query: string(b), Model: q.GetModel()}
+}
+
+func TestRedactedQuery(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, pgdialect.New())
+	note := "n0te"
+	secret := &testSecret{ID: 1, Name: "1", Value: "s3cret", Note: &note}
+
+	event := queryEvent(db, db.NewInsert().Model(secret))
+	require.Contains(t, event.Query, "'s3cret'")
+	require.Equal(t, `INSERT INTO "secrets" ("id", "name", "value", "note") VALUES (1, '1', '[REDACTED]', '[REDACTED]')`,
+		redactedQuery(event))
+
+	// The model is not modified.
+	require.Equal(t, "s3cret", secret.Value)
+	require.Equal(t, "n0te", note)
+	require.Same(t, &note, secret.Note)
+
+	// The equal values of the other fields and clauses are not redacted.
+	secrets := []*testSecret{{ID: 1, Name: "s3cret", Value: "s3cret"}, {ID: 2, Value: "1"}}
+	require.Equal(t,
+		`INSERT INTO "secrets" ("id", "name", "value", "note") VALUES (1, 's3cret', '[REDACTED]', DEFAULT), (2, '', '[REDACTED]', DEFAULT) `+
+			`RETURNING "note"`,
+		redactedQuery(queryEvent(db, db.NewInsert().Model(&secrets))),
+	)
+	require.Equal(t, "s3cret", secrets[0].Value)
+
+	q := db.NewUpdate().Model(&testSecret{ID: 1, Name: "1", Value: "1"}).Column("name", "value").Where("s.id IN (SELECT 1 LIMIT 1)").WherePK()
+	require.Equal(t,
+		`UPDATE "secrets" AS "s" SET "name" = '1', "value" = '[REDACTED]' WHERE (s.id IN (SELECT 1 LIMIT 1)) AND ("s"."id" = 1)`,
+		redactedQuery(queryEvent(db, q)),
+	)
+
+	// The arguments are redacted with Redact.
+	event = queryEvent(db, db.NewSelect().Model((*testSecret)(nil)).Where("value = ?", Redact("s3cret")).Limit(1))
+	require.Contains(t, event.Query, "'s3cret'")
+	require.Equal(t, `SELECT "s"."id", "s"."name", "s"."value", "s"."note" FROM "secrets" AS "s" WHERE (value = '[REDACTED]') LIMIT 1`,
+		redactedQuery(event))
+
+	// The fields which can't hold the marker make the whole query redacted.
+	require.Equal(t, RedactedValue, redactedQuery(queryEvent(db, db.NewInsert().Model(&testSecretCounter{ID: 1, Value: 1}))))
+
+	// The raw queries are logged as is.
+	require.Equal(t, "SELECT 1", redactedQuery(&bun.QueryEvent{DB: db, Query: "SELECT 1"}))
+}
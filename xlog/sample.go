@@ -0,0 +1,45 @@
+package xlog
+
+import (
+	"hash/maphash"
+	"math/rand/v2"
+	"sync/atomic"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/sqlnorm"
+)
+
+// Sampler decides if the query is logged.
+type Sampler func(event *bun.QueryEvent) bool
+
+// SampleRatio returns Sampler logging the given ratio of the queries at random, e.g. 0.1 for 10% of them.
+func SampleRatio(ratio float64) Sampler {
+	return func(*bun.QueryEvent) bool {
+		return rand.Float64() < ratio
+	}
+}
+
+// sampleEveryCounters is the number of the execution counters kept by SampleEvery Sampler.
+const sampleEveryCounters = 4096
+
+// SampleEvery returns Sampler logging only the first of every n executions of the same query (with the literal values ignored),
+// so the high-volume queries don't flood the logs while the rare ones are logged.
+// Executions are counted with a fixed-size table of counters indexed by the query hash to keep the memory usage bounded,
+// so distinct queries sharing a counter are sampled together.
+func SampleEvery(n uint64) Sampler {
+	var (
+		seed   = maphash.MakeSeed()
+		counts [sampleEveryCounters]atomic.Uint64
+	)
+
+	return func(event *bun.QueryEvent) bool {
+		if n <= 1 {
+			return true
+		}
+
+		i := maphash.String(seed, sqlnorm.Normalize(event.Query)) % sampleEveryCounters
+
+		return (counts[i].Add(1)-1)%n == 0
+	}
+}
@@ -0,0 +1,33 @@
+package xlog
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestSampleEvery(t *testing.T) {
+	t.Parallel()
+
+	sample := SampleEvery(3)
+
+	var logged []bool
+	for id := range 4 {
+		logged = append(logged, sample(&bun.QueryEvent{Query: "SELECT * FROM a WHERE id = " + strconv.Itoa(id)}))
+	}
+
+	require.Equal(t, []bool{true, false, false, true}, logged)
+
+	// Distinct queries beyond the number of counters don't make the sampler grow, but share the counters.
+	sampled := 0
+	for i := range 2 * sampleEveryCounters {
+		if sample(&bun.QueryEvent{Query: "SELECT * FROM t" + strconv.Itoa(i)}) {
+			sampled++
+		}
+	}
+
+	require.Less(t, sampled, 2*sampleEveryCounters)
+	require.True(t, SampleEvery(1)(&bun.QueryEvent{}))
+}
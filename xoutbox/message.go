@@ -0,0 +1,83 @@
+package xoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+)
+
+var (
+	_ xbun.HasPK[int64]         = (*Message)(nil)
+	_ bun.BeforeAppendModelHook = (*Message)(nil)
+)
+
+// Message is an outbox message, i.e. a domain event stored in the same transaction as the model changes it describes.
+// Messages are published by Relay in the order of their RunAt, which is the creation time unless they're retried,
+// with ties broken by their IDs, which are generated by the database on insert, so they're unique across all the service replicas
+// writing to the outbox without any coordination between them.
+type Message struct {
+	bun.BaseModel `bun:"table:outbox_messages,alias:om"`
+
+	xbun.PKAutoIncrement[int64]
+	xbun.Timestamps
+
+	Topic    string          `bun:"topic,notnull"`
+	Payload  json.RawMessage `bun:"payload,notnull"`
+	RunAt    time.Time       `bun:"run_at,notnull"`
+	Attempts int             `bun:"attempts,notnull"`
+	SentAt   bun.NullTime    `bun:"sent_at,nullzero"`
+}
+
+// BeforeAppendModel sets the timestamps of the message, as well as its initial RunAt on insert.
+func (m *Message) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if err := m.Timestamps.BeforeAppendModel(ctx, query); err != nil {
+		return err
+	}
+
+	if _, ok := query.(*bun.InsertQuery); ok && m.RunAt.IsZero() {
+		m.RunAt = m.CreatedAt.Time
+	}
+
+	return nil
+}
+
+// Enqueue inserts a new message with the given topic and payload marshaled to JSON.
+// The payload could be also given as json.RawMessage or []byte, which are stored as is.
+//
+// It's meant to be called within the transaction that makes the model changes, so the message is stored if and only if they're committed.
+// The ambient transaction of the context is joined, if any (see xbun.TxOrDB).
+func Enqueue(ctx context.Context, tx bun.IDB, topic string, payload any) (*Message, error) {
+	if topic == "" {
+		return nil, errors.New("empty topic")
+	}
+
+	raw, err := marshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{Topic: topic, Payload: raw}
+
+	res, err := xbun.TxOrDB(ctx, tx).NewInsert().Model(msg).Exec(ctx)
+	if err = xbun.ExpectResult(res, err, xbun.AffectedExactly(1)); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func marshalPayload(payload any) (json.RawMessage, error) {
+	switch payload := payload.(type) {
+	case json.RawMessage:
+		return payload, nil
+	case []byte:
+		return payload, nil
+	default:
+		return json.Marshal(payload)
+	}
+}
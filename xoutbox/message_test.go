@@ -0,0 +1,48 @@
+package xoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestMessage_BeforeAppendModel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var m Message
+
+	require.NoError(t, m.BeforeAppendModel(ctx, (*bun.InsertQuery)(nil)))
+	require.False(t, m.CreatedAt.IsZero())
+	require.Equal(t, m.CreatedAt.Time, m.RunAt)
+
+	runAt := time.Now().Add(time.Hour)
+	m2 := Message{RunAt: runAt}
+
+	require.NoError(t, m2.BeforeAppendModel(ctx, (*bun.InsertQuery)(nil)))
+	require.Equal(t, runAt, m2.RunAt)
+}
+
+func TestMarshalPayload(t *testing.T) {
+	t.Parallel()
+
+	raw, err := marshalPayload(map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a":1}`, string(raw))
+
+	raw, err = marshalPayload(json.RawMessage(`{"b":2}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"b":2}`, string(raw))
+
+	raw, err = marshalPayload([]byte(`[]`))
+	require.NoError(t, err)
+	require.Equal(t, `[]`, string(raw))
+
+	_, err = marshalPayload(func() {})
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package xoutbox
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Publisher = (*MemoryPublisher)(nil)
+
+// MemoryPublisher is an in-memory Publisher for tests.
+type MemoryPublisher struct {
+	// FailFunc, if set, is called before publishing every message, and the message is not published if it returns an error.
+	FailFunc func(msg *Message) error
+
+	mu        sync.Mutex
+	published []*Message
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, msg *Message) error {
+	if p.FailFunc != nil {
+		if err := p.FailFunc(msg); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.published = append(p.published, msg)
+
+	return nil
+}
+
+// Published returns the messages published so far in the order of publishing.
+func (p *MemoryPublisher) Published() []*Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*Message(nil), p.published...)
+}
+
+// Reset forgets the published messages.
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.published = nil
+}
@@ -0,0 +1,72 @@
+package xoutbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/xquery"
+)
+
+// sentAtColumn is the column Relay marks the published messages with.
+const sentAtColumn = "sent_at"
+
+// Publisher publishes outbox messages to an external system (e.g. a message broker).
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Relay publishes pending outbox messages with the Publisher and marks them as sent.
+// It claims the messages in batches with xquery.Queue, so multiple relays could run concurrently.
+//
+// Delivery is at-least-once: if publishing of any message in a batch fails, the whole batch is retried later with a backoff,
+// including the messages that were published already. Messages are published in the enqueue order (see Message),
+// but use a single relay worker with Relay.BatchSize of 1 if the strict order is required across the failures as well.
+type Relay struct {
+	Publisher Publisher
+
+	// BatchSize is the maximum number of messages claimed at once. By default, it's xquery.DefaultQueueBatchSize.
+	BatchSize int
+
+	// PollInterval is the delay between claims when there are no pending messages. By default, it's xquery.DefaultQueuePollInterval.
+	PollInterval time.Duration
+
+	// Backoff returns the delay before the next attempt of the failed message. By default, it's the one of xquery.Queue.
+	Backoff xquery.QueueBackoffFunc
+
+	// ErrorHandler is called by Run for every relay error. By default, errors are ignored.
+	ErrorHandler func(ctx context.Context, err error)
+}
+
+// Run starts the given number of relay workers and blocks until the context is done.
+func (r *Relay) Run(ctx context.Context, db bun.IDB, workers int) error {
+	return r.queue().Run(ctx, db, workers, r.publish)
+}
+
+// RelayOnce publishes a single batch of pending messages and returns the number of the claimed ones.
+func (r *Relay) RelayOnce(ctx context.Context, db bun.IDB) (int, error) {
+	return r.queue().Claim(ctx, db, r.publish)
+}
+
+func (r *Relay) queue() *xquery.Queue[*Message, []*Message] {
+	return &xquery.Queue[*Message, []*Message]{
+		BatchSize:    r.BatchSize,
+		PollInterval: r.PollInterval,
+		OnSuccess:    xquery.QueueMarkDone,
+		Backoff:      r.Backoff,
+		ErrorHandler: r.ErrorHandler,
+		DoneAtColumn: sentAtColumn,
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, _ bun.IDB, msgs []*Message) error {
+	for _, msg := range msgs {
+		if err := r.Publisher.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("publish message %d: %w", msg.ID, err)
+		}
+	}
+
+	return nil
+}
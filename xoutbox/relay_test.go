@@ -0,0 +1,35 @@
+package xoutbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay_publish(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errFail := errors.New("fail")
+
+	pub := &MemoryPublisher{}
+	relay := &Relay{Publisher: pub}
+	msgs := []*Message{{Topic: "a"}, {Topic: "b"}, {Topic: "c"}}
+
+	require.NoError(t, relay.publish(ctx, nil, msgs))
+	require.Equal(t, msgs, pub.Published())
+
+	pub.Reset()
+	pub.FailFunc = func(msg *Message) error {
+		if msg.Topic == "b" {
+			return errFail
+		}
+
+		return nil
+	}
+
+	require.ErrorIs(t, relay.publish(ctx, nil, msgs), errFail)
+	require.Equal(t, msgs[:1], pub.Published())
+}
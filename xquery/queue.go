@@ -0,0 +1,373 @@
+package xquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xerr"
+)
+
+// Queue defaults.
+const (
+	DefaultQueueBatchSize    = 10
+	DefaultQueuePollInterval = time.Second
+
+	DefaultQueueRunAtColumn    = "run_at"
+	DefaultQueueAttemptsColumn = "attempts"
+	DefaultQueueDoneAtColumn   = "done_at"
+)
+
+type (
+	// QueueHandler is a function type that handles the claimed jobs.
+	// It's being called within the claiming transaction (actually, a savepoint of it), so any changes made by the handler
+	// are committed together with the jobs completion or rolled back if the handler returns an error.
+	QueueHandler[M any, C ~[]M] func(ctx context.Context, tx bun.IDB, jobs C) error
+
+	// QueueBackoffFunc returns the delay before the next attempt to handle the job which failed the given number of times.
+	QueueBackoffFunc func(attempts int) time.Duration
+)
+
+// QueueOnSuccess defines what Queue does with the successfully handled jobs.
+type QueueOnSuccess uint8
+
+const (
+	// QueueDelete deletes the handled jobs.
+	QueueDelete QueueOnSuccess = iota
+
+	// QueueMarkDone sets the done column of the handled jobs to the current time.
+	// Jobs marked as done are not claimed anymore.
+	QueueMarkDone
+)
+
+// Queue is a database-backed job queue consumer.
+// Every claim selects up to Queue.BatchSize due jobs in the `run_at` order (ties are broken by the primary key)
+// with `FOR UPDATE SKIP LOCKED` within a transaction, so multiple workers (including ones from other processes)
+// could consume the same queue concurrently without blocking each other.
+// On SQLite, which doesn't support row-level locking, the locking clause is omitted.
+//
+// The job model must have a primary key and the following columns (names are configurable):
+// - `run_at` (time) is the time when the job becomes due;
+// - `attempts` (integer) is the number of failed attempts, it's incremented with the exponential backoff of `run_at` on failure;
+// - `done_at` (nullable time) is the time of the job completion, only required for QueueMarkDone.
+type Queue[M any, C ~[]M] struct {
+	// BuildQueryFunc should return a query that can be used to select the jobs from the database.
+	// By default, it's a simple select query that targets all jobs for a given model type.
+	//
+	// It has the same restrictions as Select.BuildQueryFunc. Due jobs filtering, ordering and locking clauses are added by Queue.
+	BuildQueryFunc SelectBuildQueryFunc[M, C]
+
+	// BatchSize is the maximum number of jobs claimed at once. By default, it's DefaultQueueBatchSize.
+	BatchSize int
+
+	// PollInterval is the delay between claims in Run when there are no due jobs. By default, it's DefaultQueuePollInterval.
+	PollInterval time.Duration
+
+	// OnSuccess defines what to do with the successfully handled jobs. By default, it's QueueDelete.
+	OnSuccess QueueOnSuccess
+
+	// Backoff returns the delay before the next attempt of the failed job.
+	// By default, it's QueueExponentialBackoff(time.Second, time.Hour).
+	Backoff QueueBackoffFunc
+
+	// ErrorHandler is called by Run for every claim error, including the ones returned (or panics raised) by the handler.
+	// By default, errors are logged with slog.Default().
+	ErrorHandler func(ctx context.Context, err error)
+
+	RunAtColumn    string
+	AttemptsColumn string
+	DoneAtColumn   string
+}
+
+// QueueExponentialBackoff returns QueueBackoffFunc doubling the delay starting from base on every attempt up to the max.
+func QueueExponentialBackoff(base, maxDelay time.Duration) QueueBackoffFunc {
+	return func(attempts int) time.Duration {
+		if attempts < 1 {
+			return base
+		}
+
+		delay := float64(base) * math.Pow(2, float64(attempts-1))
+		if delay > float64(maxDelay) {
+			return maxDelay
+		}
+
+		return time.Duration(delay)
+	}
+}
+
+// Run starts the given number of workers claiming the jobs and blocks until the context is done.
+// Claimed batches are always completed before the worker stops, so Run returns once the running handlers do (see Claim).
+func (q *Queue[M, C]) Run(ctx context.Context, db bun.IDB, workers int, handler QueueHandler[M, C]) error {
+	if workers < 1 {
+		return errors.New("invalid workers number")
+	}
+
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			q.work(ctx, db, handler)
+		}()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (q *Queue[M, C]) work(ctx context.Context, db bun.IDB, handler QueueHandler[M, C]) {
+	for ctx.Err() == nil {
+		n, err := q.Claim(ctx, db, handler)
+		if err != nil {
+			q.handleError(ctx, err)
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(q.pollInterval()):
+		}
+	}
+}
+
+// Claim claims a single batch of due jobs and passes it to the handler.
+// The claiming transaction joins the ambient one of the context as a savepoint (see xbun.RunInTx).
+// It returns the number of the claimed jobs and either the handler error (the jobs are rescheduled then) or the query execution one.
+// Handler panics are recovered and treated as errors.
+//
+// Nothing is claimed if the context is done already. Otherwise, the claiming transaction is not affected by the context cancellation,
+// so the claimed batch is always completed, but the handler is given the context cancelled along with the given one to stop early.
+// If the handler fails after the cancellation, the claim is rolled back instead of rescheduling the jobs, so their attempts are not spent.
+func (q *Queue[M, C]) Claim(ctx context.Context, db bun.IDB, handler QueueHandler[M, C]) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var (
+		n          int
+		handlerErr error
+	)
+
+	err := xbun.RunInTx(context.WithoutCancel(ctx), db, nil, func(txCtx context.Context, tx bun.Tx) error {
+		jobs, err := q.claim(txCtx, tx)
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		n = len(jobs)
+
+		handlerErr = q.handle(ctx, txCtx, tx, jobs, handler)
+		if handlerErr != nil {
+			if ctx.Err() != nil {
+				return handlerErr
+			}
+
+			return q.retry(txCtx, tx, jobs)
+		}
+
+		return q.complete(txCtx, tx, jobs)
+	})
+	if err != nil {
+		if handlerErr != nil && errors.Is(err, handlerErr) {
+			return n, fmt.Errorf("handle jobs: %w", handlerErr)
+		}
+
+		return n, err
+	}
+
+	if handlerErr != nil {
+		return n, fmt.Errorf("handle jobs: %w", handlerErr)
+	}
+
+	return n, nil
+}
+
+// handle runs the handler within a savepoint of the claiming transaction, recovering its panic.
+// The handler context carries the values of the transaction context, but it's cancelled along with the given one.
+func (q *Queue[M, C]) handle(ctx, txCtx context.Context, tx bun.Tx, jobs C, handler QueueHandler[M, C]) (err error) {
+	txCtx, cancel := context.WithCancel(txCtx)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return xbun.RunInTx(txCtx, tx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return handler(ctx, tx, jobs)
+	})
+}
+
+func (q *Queue[M, C]) handleError(ctx context.Context, err error) {
+	if q.ErrorHandler != nil {
+		q.ErrorHandler(ctx, err)
+		return
+	}
+
+	slog.ErrorContext(ctx, "xbun: queue claim failed", slog.Any("error", err))
+}
+
+// claim selects and locks the due jobs.
+func (q *Queue[M, C]) claim(ctx context.Context, tx bun.IDB) (C, error) {
+	jobs := make(C, 0, q.batchSize())
+
+	sq := q.buildQuery(tx, &jobs).
+		Where("?TableAlias.? <= ?", bun.Ident(q.runAtColumn()), time.Now().UTC()).
+		OrderExpr("?TableAlias.? ASC", bun.Ident(q.runAtColumn())).
+		OrderExpr("?TablePKs").
+		Limit(q.batchSize())
+
+	if q.OnSuccess == QueueMarkDone {
+		sq.Where("?TableAlias.? IS NULL", bun.Ident(q.doneAtColumn()))
+	}
+
+	if tx.Dialect().Name() != dialect.SQLite {
+		xbun.QueryOptions(sq, xbun.SelectLock(xbun.Lock{Wait: xbun.LockSkipLocked}))
+	}
+
+	err := xbun.ExpectSuccess(sq.Scan(ctx))
+	if err != nil && !xerr.IsAffectedRows(err) {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// retry increments the attempts counter of the failed jobs and postpones them according to the backoff.
+func (q *Queue[M, C]) retry(ctx context.Context, tx bun.IDB, jobs C) error {
+	table := modelTable[M](tx.Dialect())
+	now := time.Now().UTC()
+
+	attemptsField, err := table.Field(q.attemptsColumn())
+	if err != nil {
+		return err
+	}
+
+	runAtField, err := table.Field(q.runAtColumn())
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		strct := reflect.Indirect(reflect.ValueOf(job))
+
+		value := attemptsField.Value(strct)
+		if !value.CanInt() {
+			return errors.New("attempts column must be of a signed integer type")
+		}
+
+		attempts := int(value.Int()) + 1
+
+		if err = attemptsField.ScanValue(strct, int64(attempts)); err != nil {
+			return err
+		}
+
+		if err = runAtField.ScanValue(strct, now.Add(q.backoff(attempts))); err != nil {
+			return err
+		}
+	}
+
+	res, err := xbun.UpdateColumnsBulk(tx, &jobs, q.attemptsColumn(), q.runAtColumn()).Exec(ctx)
+
+	return xbun.ExpectResult(res, err, xbun.AffectedExactly(len(jobs)))
+}
+
+// complete applies the QueueOnSuccess action to the handled jobs.
+func (q *Queue[M, C]) complete(ctx context.Context, tx bun.IDB, jobs C) error {
+	if q.OnSuccess != QueueMarkDone {
+		res, err := tx.NewDelete().Model(&jobs).WherePK().Exec(ctx)
+		return xbun.ExpectResult(res, err, xbun.AffectedExactly(len(jobs)))
+	}
+
+	doneAtField, err := modelTable[M](tx.Dialect()).Field(q.doneAtColumn())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	for _, job := range jobs {
+		if err = doneAtField.ScanValue(reflect.Indirect(reflect.ValueOf(job)), now); err != nil {
+			return err
+		}
+	}
+
+	res, err := xbun.UpdateColumnsBulk(tx, &jobs, q.doneAtColumn()).Exec(ctx)
+
+	return xbun.ExpectResult(res, err, xbun.AffectedExactly(len(jobs)))
+}
+
+func (q *Queue[M, C]) buildQuery(db bun.IDB, jobs *C) *bun.SelectQuery {
+	if q.BuildQueryFunc != nil {
+		return q.BuildQueryFunc(db, jobs)
+	}
+
+	return db.NewSelect().Model(jobs)
+}
+
+func (q *Queue[M, C]) batchSize() int {
+	if q.BatchSize > 0 {
+		return q.BatchSize
+	}
+
+	return DefaultQueueBatchSize
+}
+
+func (q *Queue[M, C]) pollInterval() time.Duration {
+	if q.PollInterval > 0 {
+		return q.PollInterval
+	}
+
+	return DefaultQueuePollInterval
+}
+
+func (q *Queue[M, C]) backoff(attempts int) time.Duration {
+	if q.Backoff != nil {
+		return q.Backoff(attempts)
+	}
+
+	return QueueExponentialBackoff(time.Second, time.Hour)(attempts)
+}
+
+func (q *Queue[M, C]) runAtColumn() string {
+	if q.RunAtColumn != "" {
+		return q.RunAtColumn
+	}
+
+	return DefaultQueueRunAtColumn
+}
+
+func (q *Queue[M, C]) attemptsColumn() string {
+	if q.AttemptsColumn != "" {
+		return q.AttemptsColumn
+	}
+
+	return DefaultQueueAttemptsColumn
+}
+
+func (q *Queue[M, C]) doneAtColumn() string {
+	if q.DoneAtColumn != "" {
+		return q.DoneAtColumn
+	}
+
+	return DefaultQueueDoneAtColumn
+}
@@ -0,0 +1,21 @@
+package xquery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := QueueExponentialBackoff(time.Second, time.Minute)
+
+	require.Equal(t, time.Second, backoff(0))
+	require.Equal(t, time.Second, backoff(1))
+	require.Equal(t, 2*time.Second, backoff(2))
+	require.Equal(t, 32*time.Second, backoff(6))
+	require.Equal(t, time.Minute, backoff(7))
+	require.Equal(t, time.Minute, backoff(1000))
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"math"
+	"reflect"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
 
 	"github.com/heffcodex/xbun"
 	"github.com/heffcodex/xbun/xerr"
+	"github.com/heffcodex/xbun/xtrace"
 )
 
 type (
@@ -47,7 +52,11 @@ type Selector[M any, C ~[]M] interface {
 var _ Selector[*xbun.PK[int], []*xbun.PK[int]] = (*Select[int, *xbun.PK[int], []*xbun.PK[int]])(nil)
 
 // Select is a default implementation of Selector.
-type Select[ID xbun.IIDAutoIncrement, M xbun.HasPK[ID], C ~[]M] struct {
+// Soft cursor iteration requires the ID ordering to match the insertion order,
+// so use auto-incremented or time-ordered (e.g. xbun.PKUUIDv7, xbun.PKULID) primary keys with it.
+//
+// Iter and Paginate are instrumented with the xtrace spans (xtrace.SpanIter, xtrace.SpanPaginate) and iteration chunk metrics.
+type Select[ID xbun.IID, M xbun.HasPK[ID], C ~[]M] struct {
 	// IDColumnExpr is the column expression for the id column of the database model.
 	// By default, it's `?TableAlias.id`.
 	IDColumnExpr string
@@ -75,50 +84,59 @@ type Select[ID xbun.IIDAutoIncrement, M xbun.HasPK[ID], C ~[]M] struct {
 	//
 	// Some of the restrictions above however could be bypassed by applying the corresponding xbun.QueryOption's to All and Iter calls.
 	BuildQueryFunc SelectBuildQueryFunc[M, C]
+
+	// Order is the custom ordering of soft cursor iteration, the ID column is always appended to it as the tiebreaker.
+	// By default, rows are iterated in the ID order.
+	//
+	// Every spec must target a model column (see xbun.OrderSpec.Column), since the cursor is positioned by the column values of the last row.
+	// NULL values are supported with respect to the effective NULLs placement (see xbun.OrderSpec.EffectiveNulls).
+	Order []xbun.OrderSpec
+
+	// ChunkTimeout, if set, bounds every chunk query of Iter with xbun.StatementTimeout,
+	// so a query exceeding it fails the iteration with xerr.StatementTimeoutError.
+	ChunkTimeout time.Duration
 }
 
 // All implements Selector.All.
 func (s *Select[ID, M, C]) All(ctx context.Context, db bun.IDB, options ...xbun.QueryOption) (C, error) {
-	m := make(C, 0)
-	q := s.buildQuery(db, &m)
-
-	err := xbun.ExpectSuccess(xbun.QueryOptions(q, options...).Scan(ctx))
-	if err != nil {
-		return nil, err
-	}
-
-	return m, nil
+	return selectAll(ctx, db, s.buildQuery, options...)
 }
 
 // Iter implements Selector.Iter.
 // Uses either soft cursor (id > N) or native SQL CURSOR implementation.
 // See NativeCursorIter for details.
+//
+// Like other Selector methods, it joins the ambient transaction of the context (see xbun.TxOrDB).
+// Native cursor iteration runs within a savepoint of it (see xbun.RunInTx) or a new transaction if there is none.
 func (s *Select[ID, M, C]) Iter(ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C], options ...xbun.QueryOption) error {
 	if chunkSize < 1 {
 		return errors.New("invalid chunk size")
 	}
 
+	options = withChunkTimeout(s.ChunkTimeout, options)
+
 	if s.NativeCursorIter {
-		return s.iterNativeCursor(ctx, db, chunkSize, iter, options...)
+		return tracedIter(ctx, db, xtrace.CursorNative, chunkSize, iter, func(ctx context.Context, iter IterFunc[M, C]) error {
+			return s.iterNativeCursor(ctx, db, chunkSize, iter, options...)
+		})
 	}
 
-	return s.iterSoftCursor(ctx, db, chunkSize, iter, options...)
+	return tracedIter(ctx, db, xtrace.CursorSoft, chunkSize, iter, func(ctx context.Context, iter IterFunc[M, C]) error {
+		return s.iterSoftCursor(ctx, db, chunkSize, iter, options...)
+	})
 }
 
 func (s *Select[ID, M, C]) iterNativeCursor(
 	ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C], options ...xbun.QueryOption,
 ) error {
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return xerr.ErrQueryExecution(err)
-	}
-
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		}
-	}()
+	return xbun.RunInTx(ctx, db, nil, func(ctx context.Context, tx bun.Tx) error {
+		return s.iterNativeCursorTx(ctx, tx, chunkSize, iter, options...)
+	})
+}
 
+func (s *Select[ID, M, C]) iterNativeCursorTx(
+	ctx context.Context, tx bun.Tx, chunkSize int, iter IterFunc[M, C], options ...xbun.QueryOption,
+) error {
 	chunkModel := make(C, 0, chunkSize)
 	idDest := make([]ID, 0, chunkSize)
 
@@ -129,7 +147,11 @@ func (s *Select[ID, M, C]) iterNativeCursor(
 	qCursor := tx.NewRaw("DECLARE ? NO SCROLL CURSOR WITHOUT HOLD FOR ?", cursorName, qSelectID)
 	qFetchID := tx.NewRaw("FETCH FORWARD ? FROM ?", chunkSize, cursorName)
 
-	err = xbun.ExpectResult(qCursor.Exec(ctx))
+	if s.ChunkTimeout > 0 {
+		qFetchID = xbun.QueryOptions(qFetchID, xbun.StatementTimeout(s.ChunkTimeout))
+	}
+
+	err := xbun.ExpectResult(qCursor.Exec(ctx))
 	if err != nil {
 		return err
 	}
@@ -171,21 +193,270 @@ func (s *Select[ID, M, C]) iterNativeCursor(
 		clear(chunkModel)
 	}
 
-	return tx.Commit()
+	// The cursor is closed explicitly, since the ambient transaction may outlive the iteration.
+	return xbun.ExpectResult(tx.NewRaw("CLOSE ?", cursorName).Exec(ctx))
 }
 
 func (s *Select[ID, M, C]) iterSoftCursor(
 	ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C], options ...xbun.QueryOption,
 ) error {
-	cursor := ID(0)
-	idColumnExpr := s.idColumnExpr()
+	var cursor softCursor[M] = &idCursor[ID, M]{expr: s.idColumnExpr()}
+	if len(s.Order) > 0 {
+		cursor = &orderCursor[ID, M]{specs: s.Order, idExpr: s.idColumnExpr()}
+	}
+
+	return iterSoftCursor(ctx, db, chunkSize, iter, s.buildQuery, cursor, options...)
+}
+
+// Paginate implements Selector.Paginate.
+func (s *Select[ID, M, C]) Paginate(
+	ctx context.Context, db bun.IDB,
+	page, perPage uint,
+	options ...xbun.QueryOption,
+) (*SelectPaginatedResult[M, C], error) {
+	return tracedPaginate(ctx, db, page, perPage, func(ctx context.Context) (*SelectPaginatedResult[M, C], error) {
+		return selectPaginate(ctx, db, s.buildQuery, page, perPage, options...)
+	})
+}
+
+// idColumnExpr returns the column expression for the id column of the database model.
+func (s *Select[ID, M, C]) idColumnExpr() string {
+	if s.IDColumnExpr != "" {
+		return s.IDColumnExpr
+	}
+
+	return "?TableAlias.id"
+}
+
+// buildQuery returns a query that can be used to select every chunk of rows from the database.
+func (s *Select[ID, M, C]) buildQuery(db bun.IDB, chunkModel *C) *bun.SelectQuery {
+	if s.BuildQueryFunc != nil {
+		return s.BuildQueryFunc(db, chunkModel)
+	}
+
+	return db.NewSelect().Model(chunkModel)
+}
+
+// -----------------------------------------------------------------------------------------------------------------------------------------
+
+// softCursor is a keyset-based cursor used by iterSoftCursor.
+type softCursor[M any] interface {
+	// apply adds ordering and, if the cursor is already positioned, filtering clauses to the chunk query.
+	apply(q *bun.SelectQuery)
+
+	// advance positions the cursor after the given row.
+	advance(last M)
+}
+
+var _ softCursor[*xbun.PK[int]] = (*idCursor[int, *xbun.PK[int]])(nil)
+
+// idCursor is a softCursor over a single identifier column.
+type idCursor[ID xbun.IID, M xbun.HasPK[ID]] struct {
+	expr     string
+	value    ID
+	hasValue bool
+}
+
+func (c *idCursor[ID, M]) apply(q *bun.SelectQuery) {
+	q.OrderExpr(xbun.OrderExpr(c.expr, xbun.OrderAsc))
+
+	if c.hasValue {
+		q.Where(c.expr+" > ?", c.value)
+	}
+}
+
+func (c *idCursor[ID, M]) advance(last M) {
+	c.value, c.hasValue = last.GetPK(), true
+}
+
+var _ softCursor[*xbun.PK[int]] = (*orderCursor[int, *xbun.PK[int]])(nil)
+
+// orderCursor is a softCursor over the custom order specs followed by the identifier column as the tiebreaker.
+type orderCursor[ID xbun.IID, M xbun.HasPK[ID]] struct {
+	specs  []xbun.OrderSpec
+	idExpr string
+
+	dialect schema.Dialect
+	fields  []*schema.Field
+	err     error
+
+	values   []string // SQL literals of the last row column values, "" for NULL
+	id       ID
+	hasValue bool
+}
+
+func (c *orderCursor[ID, M]) apply(q *bun.SelectQuery) {
+	if c.fields == nil && c.err == nil {
+		c.resolve(q.Dialect())
+	}
+
+	if c.err != nil {
+		q.Err(c.err)
+		return
+	}
+
+	for _, spec := range c.specs {
+		q.OrderExpr("?", spec)
+	}
+
+	q.OrderExpr(xbun.OrderExpr(c.idExpr, xbun.OrderAsc))
+
+	if c.hasValue {
+		q.WhereGroup(xbun.SepAND, func(q *bun.SelectQuery) *bun.SelectQuery {
+			for i := 0; i <= len(c.specs); i++ {
+				c.applyAfter(q, i)
+			}
+
+			return q
+		})
+	}
+}
+
+// resolve looks up the model fields of the order specs.
+func (c *orderCursor[ID, M]) resolve(dialect schema.Dialect) {
+	table := modelTable[M](dialect)
+
+	c.dialect = dialect
+	c.fields = make([]*schema.Field, len(c.specs))
+
+	for i, spec := range c.specs {
+		if spec.Column == "" {
+			c.err = errors.New("soft cursor order requires column specs")
+			return
+		}
+
+		f, err := table.Field(spec.Column)
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		c.fields[i] = f
+	}
+}
+
+// applyAfter adds the OR-joined condition matching the rows equal to the last one in the first i columns and following it in the i-th one
+// (or in the identifier if i is the number of specs).
+func (c *orderCursor[ID, M]) applyAfter(q *bun.SelectQuery, i int) {
+	var (
+		after string
+		args  []any
+	)
+
+	if i < len(c.specs) {
+		after, args = c.after(i)
+		if after == "" {
+			return // nothing follows NULL placed last
+		}
+	} else {
+		after, args = c.idExpr+" > ?", []any{c.id}
+	}
+
+	q.WhereGroup(xbun.SepOR, func(q *bun.SelectQuery) *bun.SelectQuery {
+		for j := range i {
+			expr, exprArgs := c.expr(j)
+
+			if c.values[j] == "" {
+				q.Where(expr+" IS NULL", exprArgs...)
+			} else {
+				q.Where(expr+" = ?", append(exprArgs, bun.Safe(c.values[j]))...)
+			}
+		}
+
+		return q.Where(after, args...)
+	})
+}
+
+// after returns the condition matching the rows following the last one in the i-th column, or "" if there are no such rows.
+func (c *orderCursor[ID, M]) after(i int) (string, []any) {
+	expr, args := c.expr(i)
+	nullsLast := c.specs[i].EffectiveNulls(c.dialect.Name()) == xbun.NullsLast
+
+	if c.values[i] == "" {
+		if nullsLast {
+			return "", nil
+		}
+
+		return expr + " IS NOT NULL", args
+	}
+
+	op := " > ?"
+	if c.specs[i].Dir == xbun.OrderDesc {
+		op = " < ?"
+	}
+
+	query := expr + op
+	queryArgs := append(slices.Clone(args), bun.Safe(c.values[i]))
+
+	if nullsLast {
+		query += " OR " + expr + " IS NULL"
+		queryArgs = append(queryArgs, args...)
+	}
+
+	return query, queryArgs
+}
+
+// expr returns the i-th column expression including its collation.
+func (c *orderCursor[ID, M]) expr(i int) (string, []any) {
+	spec := c.specs[i]
+
+	if spec.Collate != "" {
+		return "?TableAlias.? COLLATE ?", []any{bun.Ident(spec.Column), bun.Ident(spec.Collate)}
+	}
+
+	return "?TableAlias.?", []any{bun.Ident(spec.Column)}
+}
+
+func (c *orderCursor[ID, M]) advance(last M) {
+	fmter := schema.NewFormatter(c.dialect)
+	strct := reflect.Indirect(reflect.ValueOf(last))
+
+	c.values = make([]string, len(c.fields))
+
+	for i, f := range c.fields {
+		if v := string(f.AppendValue(fmter, nil, strct)); v != "NULL" {
+			c.values[i] = v
+		}
+	}
+
+	c.id, c.hasValue = last.GetPK(), true
+}
+
+// selectAll implements Selector.All for the given query builder.
+func selectAll[M any, C ~[]M](
+	ctx context.Context, db bun.IDB, build SelectBuildQueryFunc[M, C], options ...xbun.QueryOption,
+) (C, error) {
+	m := make(C, 0)
+	q := build(xbun.TxOrDB(ctx, db), &m)
+
+	err := xbun.ExpectSuccess(xbun.QueryOptions(q, options...).Scan(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// withChunkTimeout returns the options with xbun.StatementTimeout appended if the timeout is set.
+func withChunkTimeout(timeout time.Duration, options []xbun.QueryOption) []xbun.QueryOption {
+	if timeout <= 0 {
+		return options
+	}
+
+	return append(slices.Clip(options), xbun.StatementTimeout(timeout))
+}
+
+// iterSoftCursor implements Selector.Iter for the given query builder using the keyset-based cursor.
+func iterSoftCursor[M any, C ~[]M](
+	ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C],
+	build SelectBuildQueryFunc[M, C], cursor softCursor[M], options ...xbun.QueryOption,
+) error {
+	db = xbun.TxOrDB(ctx, db)
 	chunkModel := make(C, 0, chunkSize)
 
 	for next := true; next; {
-		q := s.buildQuery(db, &chunkModel).
-			Where(idColumnExpr+" > ?", cursor).
-			OrderExpr(xbun.OrderExpr(idColumnExpr, xbun.OrderAsc)).
-			Limit(chunkSize)
+		q := build(db, &chunkModel).Limit(chunkSize)
+		cursor.apply(q)
 
 		err := xbun.ExpectSuccess(xbun.QueryOptions(q, options...).Scan(ctx))
 		if xerr.IsAffectedRows(err) {
@@ -207,7 +478,7 @@ func (s *Select[ID, M, C]) iterSoftCursor(
 			break
 		}
 
-		cursor = chunkModel[len(chunkModel)-1].GetPK()
+		cursor.advance(chunkModel[len(chunkModel)-1])
 
 		clear(chunkModel)
 	}
@@ -215,14 +486,14 @@ func (s *Select[ID, M, C]) iterSoftCursor(
 	return nil
 }
 
-// Paginate implements Selector.Paginate.
-func (s *Select[ID, M, C]) Paginate(
-	ctx context.Context, db bun.IDB,
+// selectPaginate implements Selector.Paginate for the given query builder.
+func selectPaginate[M any, C ~[]M](
+	ctx context.Context, db bun.IDB, build SelectBuildQueryFunc[M, C],
 	page, perPage uint,
 	options ...xbun.QueryOption,
 ) (*SelectPaginatedResult[M, C], error) {
 	m := make(C, 0)
-	q := s.buildQuery(db, &m)
+	q := build(xbun.TxOrDB(ctx, db), &m)
 
 	opts := append([]xbun.QueryOption{xbun.Paginate(page, perPage)}, options...)
 	count, err := xbun.QueryOptions(q, opts...).ScanAndCount(ctx)
@@ -245,21 +516,3 @@ func (s *Select[ID, M, C]) Paginate(
 		Chunk:         m,
 	}, nil
 }
-
-// idColumnExpr returns the column expression for the id column of the database model.
-func (s *Select[ID, M, C]) idColumnExpr() string {
-	if s.IDColumnExpr != "" {
-		return s.IDColumnExpr
-	}
-
-	return "?TableAlias.id"
-}
-
-// buildQuery returns a query that can be used to select every chunk of rows from the database.
-func (s *Select[ID, M, C]) buildQuery(db bun.IDB, chunkModel *C) *bun.SelectQuery {
-	if s.BuildQueryFunc != nil {
-		return s.BuildQueryFunc(db, chunkModel)
-	}
-
-	return db.NewSelect().Model(chunkModel)
-}
@@ -0,0 +1,144 @@
+package xquery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xtrace"
+)
+
+var _ Selector[xbun.HasCompositePK, []xbun.HasCompositePK] = (*SelectComposite[xbun.HasCompositePK, []xbun.HasCompositePK])(nil)
+
+// SelectComposite is an implementation of Selector for models with composite primary keys (see xbun.HasCompositePK).
+// Iter uses soft cursor over the primary key tuple with row-value comparison, e.g. `(a, b) > (?, ?)`,
+// so the ordering of the tuple must match the insertion order just like for Select.
+// Iter and Paginate are instrumented just like for Select.
+type SelectComposite[M xbun.HasCompositePK, C ~[]M] struct {
+	// BuildQueryFunc should return a query that can be used to select every chunk of rows from the database.
+	// By default, it's a simple select query that targets all rows for a given chunk model type.
+	//
+	// It has the same restrictions as Select.BuildQueryFunc.
+	BuildQueryFunc SelectBuildQueryFunc[M, C]
+
+	// ChunkTimeout, if set, bounds every chunk query of Iter with xbun.StatementTimeout just like Select.ChunkTimeout.
+	ChunkTimeout time.Duration
+}
+
+// All implements Selector.All.
+func (s *SelectComposite[M, C]) All(ctx context.Context, db bun.IDB, options ...xbun.QueryOption) (C, error) {
+	return selectAll(ctx, db, s.buildQuery, options...)
+}
+
+// Iter implements Selector.Iter.
+func (s *SelectComposite[M, C]) Iter(
+	ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C], options ...xbun.QueryOption,
+) error {
+	if chunkSize < 1 {
+		return errors.New("invalid chunk size")
+	}
+
+	columns := newModel[M]().GetCompositePK()
+	if len(columns) == 0 {
+		return errors.New("empty composite primary key")
+	}
+
+	options = withChunkTimeout(s.ChunkTimeout, options)
+
+	return tracedIter(ctx, db, xtrace.CursorSoft, chunkSize, iter, func(ctx context.Context, iter IterFunc[M, C]) error {
+		return iterSoftCursor(ctx, db, chunkSize, iter, s.buildQuery, newTupleCursor[M](columns), options...)
+	})
+}
+
+// Paginate implements Selector.Paginate.
+func (s *SelectComposite[M, C]) Paginate(
+	ctx context.Context, db bun.IDB,
+	page, perPage uint,
+	options ...xbun.QueryOption,
+) (*SelectPaginatedResult[M, C], error) {
+	return tracedPaginate(ctx, db, page, perPage, func(ctx context.Context) (*SelectPaginatedResult[M, C], error) {
+		return selectPaginate(ctx, db, s.buildQuery, page, perPage, options...)
+	})
+}
+
+// buildQuery returns a query that can be used to select every chunk of rows from the database.
+func (s *SelectComposite[M, C]) buildQuery(db bun.IDB, chunkModel *C) *bun.SelectQuery {
+	if s.BuildQueryFunc != nil {
+		return s.BuildQueryFunc(db, chunkModel)
+	}
+
+	return db.NewSelect().Model(chunkModel)
+}
+
+// -----------------------------------------------------------------------------------------------------------------------------------------
+
+var _ softCursor[xbun.HasCompositePK] = (*tupleCursor[xbun.HasCompositePK])(nil)
+
+// tupleCursor is a softCursor over the composite primary key columns.
+type tupleCursor[M xbun.HasCompositePK] struct {
+	columns  []any
+	order    string
+	where    string
+	values   []any
+	hasValue bool
+}
+
+func newTupleCursor[M xbun.HasCompositePK](columns []xbun.PKColumn) *tupleCursor[M] {
+	c := &tupleCursor[M]{columns: make([]any, len(columns))}
+
+	exprs := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+
+	for i, col := range columns {
+		c.columns[i] = bun.Ident(col.Column)
+		exprs[i] = "?TableAlias.?"
+		placeholders[i] = "?"
+	}
+
+	c.order = strings.Join(exprs, " "+string(xbun.OrderAsc)+", ") + " " + string(xbun.OrderAsc)
+	c.where = "(" + strings.Join(exprs, ", ") + ") > (" + strings.Join(placeholders, ", ") + ")"
+
+	return c
+}
+
+func (c *tupleCursor[M]) apply(q *bun.SelectQuery) {
+	q.OrderExpr(c.order, c.columns...)
+
+	if c.hasValue {
+		q.Where(c.where, append(c.columns, c.values...)...)
+	}
+}
+
+func (c *tupleCursor[M]) advance(last M) {
+	pk := last.GetCompositePK()
+
+	c.values = make([]any, len(pk))
+	for i, col := range pk {
+		c.values[i] = col.Value
+	}
+
+	c.hasValue = true
+}
+
+// newModel returns a new zero model instance.
+// If M is a pointer type, it's being allocated, so methods could be called on it safely.
+func newModel[M any]() M {
+	var m M
+
+	if typ := reflect.TypeOf(m); typ != nil && typ.Kind() == reflect.Pointer {
+		return reflect.New(typ.Elem()).Interface().(M)
+	}
+
+	return m
+}
+
+// modelTable returns bun's table metadata for the model type M.
+func modelTable[M any](dialect schema.Dialect) *schema.Table {
+	return dialect.Tables().Get(reflect.Indirect(reflect.ValueOf(newModel[M]())).Type())
+}
@@ -0,0 +1,43 @@
+package xquery
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/heffcodex/xbun"
+)
+
+type testMembership struct {
+	bun.BaseModel `bun:"table:memberships,alias:ms"`
+
+	UserID  int64 `bun:"user_id,pk"`
+	GroupID int64 `bun:"group_id,pk"`
+}
+
+func (m *testMembership) GetCompositePK() []xbun.PKColumn {
+	return []xbun.PKColumn{{Column: "user_id", Value: m.UserID}, {Column: "group_id", Value: m.GroupID}}
+}
+
+func TestTupleCursor(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, pgdialect.New())
+	cursor := newTupleCursor[*testMembership](newModel[*testMembership]().GetCompositePK())
+
+	q := db.NewSelect().Model((*testMembership)(nil))
+	cursor.apply(q)
+	require.Equal(t, `SELECT "ms"."user_id", "ms"."group_id" FROM "memberships" AS "ms" `+
+		`ORDER BY "ms"."user_id" ASC, "ms"."group_id" ASC`, q.String())
+
+	cursor.advance(&testMembership{UserID: 1, GroupID: 2})
+
+	q = db.NewSelect().Model((*testMembership)(nil))
+	cursor.apply(q)
+	require.Equal(t, `SELECT "ms"."user_id", "ms"."group_id" FROM "memberships" AS "ms" `+
+		`WHERE (("ms"."user_id", "ms"."group_id") > (1, 2)) `+
+		`ORDER BY "ms"."user_id" ASC, "ms"."group_id" ASC`, q.String())
+}
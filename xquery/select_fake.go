@@ -0,0 +1,136 @@
+package xquery
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+)
+
+var _ Selector[*xbun.PK[int], []*xbun.PK[int]] = (*FakeSelector[*xbun.PK[int], []*xbun.PK[int]])(nil)
+
+// FakeSelector is an in-memory implementation of Selector backed by a slice, meant for unit tests without a database.
+// It follows the same iteration and pagination contracts as Select, while the given xbun.QueryOption's are ignored:
+// use Filter and Compare to stand in for them.
+type FakeSelector[M any, C ~[]M] struct {
+	// Rows are the rows to select from. They're never modified.
+	Rows C
+
+	// Filter, if set, reports whether the row matches the query.
+	Filter func(m M) bool
+
+	// Compare, if set, orders the matched rows like slices.SortStableFunc does.
+	// By default, the order of Rows is kept.
+	Compare func(a, b M) int
+
+	// Err, if set, is returned by every call instead of selecting, e.g. to simulate a database failure.
+	Err error
+}
+
+// All implements Selector.All.
+func (s *FakeSelector[M, C]) All(_ context.Context, _ bun.IDB, _ ...xbun.QueryOption) (C, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	return s.rows(), nil
+}
+
+// Iter implements Selector.Iter.
+// Like Select.Iter, it reuses the chunk buffer between the calls to IterFunc.
+func (s *FakeSelector[M, C]) Iter(
+	ctx context.Context, db bun.IDB, chunkSize int, iter IterFunc[M, C], _ ...xbun.QueryOption,
+) error {
+	if chunkSize < 1 {
+		return errors.New("invalid chunk size")
+	}
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	db = xbun.TxOrDB(ctx, db)
+	rows := s.rows()
+	chunk := make(C, 0, chunkSize)
+
+	for offset, next := 0, true; next && offset < len(rows); offset += chunkSize {
+		clear(chunk)
+		chunk = append(chunk[:0], rows[offset:min(offset+chunkSize, len(rows))]...)
+
+		var err error
+
+		next, err = iter(ctx, db, chunk)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Paginate implements Selector.Paginate.
+// Just like Select.Paginate, it returns an empty chunk with EffectivePage set to 1 if the page is out of range.
+func (s *FakeSelector[M, C]) Paginate(
+	_ context.Context, _ bun.IDB,
+	page, perPage uint,
+	_ ...xbun.QueryOption,
+) (*SelectPaginatedResult[M, C], error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	rows := s.rows()
+	chunk := make(C, 0)
+
+	if page > 0 {
+		// Zero perPage means no limit, just like for xbun.Paginate.
+		start, end := uint(len(rows)), uint(len(rows))
+		if offset := (page - 1) * perPage; offset < start {
+			start = offset
+		}
+
+		if perPage > 0 && start+perPage < end {
+			end = start + perPage
+		}
+
+		chunk = append(chunk, rows[start:end]...)
+	}
+
+	effectivePage := page
+	if len(chunk) == 0 {
+		effectivePage = 1
+	}
+
+	var totalPages uint
+	if perPage > 0 {
+		totalPages = uint(math.Ceil(float64(len(rows)) / float64(perPage)))
+	}
+
+	return &SelectPaginatedResult[M, C]{
+		Total:         uint(len(rows)),
+		TotalPages:    totalPages,
+		EffectivePage: effectivePage,
+		Chunk:         chunk,
+	}, nil
+}
+
+// rows returns a new slice with the matched rows in order.
+func (s *FakeSelector[M, C]) rows() C {
+	rows := make(C, 0, len(s.Rows))
+
+	for _, m := range s.Rows {
+		if s.Filter == nil || s.Filter(m) {
+			rows = append(rows, m)
+		}
+	}
+
+	if s.Compare != nil {
+		slices.SortStableFunc(rows, s.Compare)
+	}
+
+	return rows
+}
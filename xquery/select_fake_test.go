@@ -0,0 +1,112 @@
+package xquery
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun"
+)
+
+func testFakeSelector() *FakeSelector[*xbun.PK[int], []*xbun.PK[int]] {
+	rows := make([]*xbun.PK[int], 0, 10)
+	for i := 10; i > 0; i-- {
+		rows = append(rows, &xbun.PK[int]{ID: i})
+	}
+
+	return &FakeSelector[*xbun.PK[int], []*xbun.PK[int]]{
+		Rows:    rows,
+		Filter:  func(m *xbun.PK[int]) bool { return m.ID%2 == 1 },
+		Compare: func(a, b *xbun.PK[int]) int { return cmp.Compare(a.ID, b.ID) },
+	}
+}
+
+func fakeIDs(rows []*xbun.PK[int]) []int {
+	ids := make([]int, len(rows))
+	for i, m := range rows {
+		ids[i] = m.ID
+	}
+
+	return ids
+}
+
+func TestFakeSelector_All(t *testing.T) {
+	t.Parallel()
+
+	s := testFakeSelector()
+
+	rows, err := s.All(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3, 5, 7, 9}, fakeIDs(rows))
+	require.Equal(t, 10, s.Rows[0].ID, "rows must not be modified")
+
+	s.Err = errors.New("fail")
+	_, err = s.All(context.Background(), nil)
+	require.ErrorIs(t, err, s.Err)
+}
+
+func TestFakeSelector_Iter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := testFakeSelector()
+
+	var chunks [][]int
+
+	collect := func(limit int) IterFunc[*xbun.PK[int], []*xbun.PK[int]] {
+		return func(_ context.Context, _ bun.IDB, chunk []*xbun.PK[int]) (bool, error) {
+			chunks = append(chunks, fakeIDs(chunk))
+			return len(chunks) < limit, nil
+		}
+	}
+
+	require.NoError(t, s.Iter(ctx, nil, 2, collect(10)))
+	require.Equal(t, [][]int{{1, 3}, {5, 7}, {9}}, chunks)
+
+	chunks = nil
+	require.NoError(t, s.Iter(ctx, nil, 2, collect(1)))
+	require.Equal(t, [][]int{{1, 3}}, chunks)
+
+	errIter := errors.New("iter")
+	err := s.Iter(ctx, nil, 2, func(context.Context, bun.IDB, []*xbun.PK[int]) (bool, error) { return true, errIter })
+	require.ErrorIs(t, err, errIter)
+
+	require.Error(t, s.Iter(ctx, nil, 0, collect(10)))
+}
+
+func TestFakeSelector_Paginate(t *testing.T) {
+	t.Parallel()
+
+	s := testFakeSelector()
+
+	tests := []struct {
+		name          string
+		page, perPage uint
+		ids           []int
+		totalPages    uint
+		effectivePage uint
+	}{
+		{"first", 1, 2, []int{1, 3}, 3, 1},
+		{"last", 3, 2, []int{9}, 3, 3},
+		{"out of range", 4, 2, []int{}, 3, 1},
+		{"zero page", 0, 2, []int{}, 3, 1},
+		{"no limit", 1, 0, []int{1, 3, 5, 7, 9}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := s.Paginate(context.Background(), nil, tt.page, tt.perPage)
+			require.NoError(t, err)
+			require.Equal(t, tt.ids, fakeIDs(res.Chunk))
+			require.EqualValues(t, 5, res.Total)
+			require.Equal(t, tt.totalPages, res.TotalPages)
+			require.Equal(t, tt.effectivePage, res.EffectivePage)
+		})
+	}
+}
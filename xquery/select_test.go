@@ -0,0 +1,137 @@
+package xquery
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/schema"
+
+	"github.com/heffcodex/xbun"
+)
+
+type testArticle struct {
+	bun.BaseModel `bun:"table:articles,alias:a"`
+
+	xbun.PK[int64]
+
+	Title       string       `bun:"title"`
+	PublishedAt bun.NullTime `bun:"published_at,nullzero"`
+}
+
+func TestOrderCursor(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, pgdialect.New())
+	cursor := &orderCursor[int64, *testArticle]{
+		specs: []xbun.OrderSpec{
+			{Column: "published_at", Dir: xbun.OrderDesc, Nulls: xbun.NullsLast},
+			{Column: "title", Collate: "C"},
+		},
+		idExpr: "?TableAlias.id",
+	}
+
+	q := db.NewSelect().Model((*testArticle)(nil))
+	cursor.apply(q)
+	require.Equal(t, `SELECT "a"."title", "a"."published_at", "a"."id" FROM "articles" AS "a" `+
+		`ORDER BY "a"."published_at" DESC NULLS LAST, "a"."title" COLLATE "C" ASC, "a".id ASC`, q.String())
+
+	cursor.advance(&testArticle{PK: xbun.PK[int64]{ID: 5}, Title: "x", PublishedAt: bun.NullTime{Time: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)}})
+
+	q = db.NewSelect().Model((*testArticle)(nil))
+	cursor.apply(q)
+	require.Equal(t, `SELECT "a"."title", "a"."published_at", "a"."id" FROM "articles" AS "a" WHERE (`+
+		`(("a"."published_at" < '2024-01-02 03:04:05+00:00' OR "a"."published_at" IS NULL)) OR `+
+		`(("a"."published_at" = '2024-01-02 03:04:05+00:00') AND ("a"."title" COLLATE "C" > 'x' OR "a"."title" COLLATE "C" IS NULL)) OR `+
+		`(("a"."published_at" = '2024-01-02 03:04:05+00:00') AND ("a"."title" COLLATE "C" = 'x') AND ("a".id > 5))) `+
+		`ORDER BY "a"."published_at" DESC NULLS LAST, "a"."title" COLLATE "C" ASC, "a".id ASC`, q.String())
+
+	cursor.advance(&testArticle{PK: xbun.PK[int64]{ID: 7}, Title: "y"})
+
+	q = db.NewSelect().Model((*testArticle)(nil))
+	cursor.apply(q)
+	require.Equal(t, `SELECT "a"."title", "a"."published_at", "a"."id" FROM "articles" AS "a" WHERE (`+
+		`(("a"."published_at" IS NULL) AND ("a"."title" COLLATE "C" > 'y' OR "a"."title" COLLATE "C" IS NULL)) OR `+
+		`(("a"."published_at" IS NULL) AND ("a"."title" COLLATE "C" = 'y') AND ("a".id > 7))) `+
+		`ORDER BY "a"."published_at" DESC NULLS LAST, "a"."title" COLLATE "C" ASC, "a".id ASC`, q.String())
+}
+
+func TestOrderCursor_error(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, pgdialect.New())
+
+	for _, spec := range []xbun.OrderSpec{{Expr: "lower(title)"}, {Column: "unknown"}} {
+		cursor := &orderCursor[int64, *testArticle]{specs: []xbun.OrderSpec{spec}, idExpr: "?TableAlias.id"}
+
+		q := db.NewSelect().Model((*testArticle)(nil))
+		cursor.apply(q)
+
+		_, err := q.AppendQuery(schema.NewFormatter(db.Dialect()), nil)
+		require.Error(t, err)
+	}
+}
+
+// captureConn is bun.IConn capturing the executed queries instead of running them.
+type captureConn struct {
+	queries *[]string
+}
+
+func (c captureConn) QueryContext(_ context.Context, query string, _ ...any) (*sql.Rows, error) {
+	*c.queries = append(*c.queries, query)
+	return nil, errCaptured
+}
+
+func (c captureConn) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return nil, errCaptured
+}
+
+func (c captureConn) QueryRowContext(_ context.Context, query string, _ ...any) *sql.Row {
+	*c.queries = append(*c.queries, query)
+	return nil
+}
+
+var errCaptured = errors.New("captured")
+
+// errConnector is driver.Connector failing to connect, so the dialects probing the server version on init don't panic.
+type errConnector struct{}
+
+func (errConnector) Connect(context.Context) (driver.Conn, error) { return nil, errCaptured }
+func (errConnector) Driver() driver.Driver                        { return nil }
+
+func TestSelect_ChunkTimeout(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+
+	conn := captureConn{queries: &queries}
+	options := make([]xbun.QueryOption, 1, 2)
+	options[0] = xbun.SQLComment(nil)
+
+	s := &Select[int64, *testArticle, []*testArticle]{
+		ChunkTimeout: 2 * time.Second,
+		BuildQueryFunc: func(db bun.IDB, chunkModel *[]*testArticle) *bun.SelectQuery {
+			return db.NewSelect().Conn(conn).Model(chunkModel)
+		},
+	}
+
+	iter := func(context.Context, bun.IDB, []*testArticle) (bool, error) { return true, nil }
+
+	// The test database has no PostgreSQL driver supporting the timeout, so MySQL is used.
+	err := s.Iter(context.Background(), bun.NewDB(sql.OpenDB(errConnector{}), mysqldialect.New()), 2, iter, options...)
+	require.ErrorIs(t, err, errCaptured)
+	require.Len(t, options[:cap(options)], 2)
+	require.Nil(t, options[:cap(options)][1], "caller options must not be modified")
+
+	require.Equal(t, []string{
+		"SELECT /*+ MAX_EXECUTION_TIME(2000) */ `a`.`title`, `a`.`published_at`, `a`.`id` FROM `articles` AS `a` ORDER BY `a`.id ASC LIMIT 2",
+	}, queries)
+}
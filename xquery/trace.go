@@ -0,0 +1,102 @@
+package xquery
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xtrace"
+)
+
+// tracedIter runs the iteration within the xtrace.SpanIter span counting its chunks and rows.
+func tracedIter[M any, C ~[]M](
+	ctx context.Context, db bun.IDB, cursor string, chunkSize int, iter IterFunc[M, C],
+	run func(ctx context.Context, iter IterFunc[M, C]) error,
+) error {
+	cursorAttr := xtrace.A(xtrace.AttrIterCursor, cursor)
+
+	ctx, span := xtrace.StartSpan(ctx, xtrace.SpanIter, tableAttrs[M](ctx, db,
+		cursorAttr,
+		xtrace.A(xtrace.AttrIterChunkSize, chunkSize),
+	)...)
+	defer span.End()
+
+	meter := xtrace.FromContext(ctx).Meter
+
+	var chunks, rows int
+
+	err := run(ctx, func(ctx context.Context, tx bun.IDB, chunk C) (bool, error) {
+		chunks++
+		rows += len(chunk)
+
+		meter.Add(ctx, xtrace.MetricIterChunks, 1, cursorAttr)
+		meter.Record(ctx, xtrace.MetricIterChunkRows, float64(len(chunk)), cursorAttr)
+
+		return iter(ctx, tx, chunk)
+	})
+
+	span.SetAttributes(xtrace.A(xtrace.AttrIterChunks, chunks), xtrace.A(xtrace.AttrIterRows, rows))
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// tracedPaginate runs the pagination within the xtrace.SpanPaginate span.
+func tracedPaginate[M any, C ~[]M](
+	ctx context.Context, db bun.IDB, page, perPage uint,
+	run func(ctx context.Context) (*SelectPaginatedResult[M, C], error),
+) (*SelectPaginatedResult[M, C], error) {
+	ctx, span := xtrace.StartSpan(ctx, xtrace.SpanPaginate, tableAttrs[M](ctx, db,
+		xtrace.A(xtrace.AttrPaginatePage, page),
+		xtrace.A(xtrace.AttrPaginatePerPage, perPage),
+	)...)
+	defer span.End()
+
+	res, err := run(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		xtrace.A(xtrace.AttrPaginateTotal, res.Total),
+		xtrace.A(xtrace.AttrPaginateTotalPages, res.TotalPages),
+		xtrace.A(xtrace.AttrPaginateEffectivePage, res.EffectivePage),
+	)
+
+	return res, nil
+}
+
+// tableAttrs returns the given span attributes followed by the table name of the model type M.
+// The table is only looked up if the spans are recorded and it's omitted if M is not a struct (e.g. an interface).
+func tableAttrs[M any](ctx context.Context, db bun.IDB, attrs ...xtrace.Attr) []xtrace.Attr {
+	if !xtrace.FromContext(ctx).Tracing() {
+		return attrs
+	}
+
+	if name := modelTableName[M](xbun.TxOrDB(ctx, db).Dialect()); name != "" {
+		attrs = append(attrs, xtrace.A(xtrace.AttrDBTable, name))
+	}
+
+	return attrs
+}
+
+// modelTableName returns the table name of the model type M, or an empty string if it's not a struct or a pointer to one.
+func modelTableName[M any](dialect schema.Dialect) string {
+	typ := reflect.TypeFor[M]()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return ""
+	}
+
+	return dialect.Tables().Get(typ).Name
+}
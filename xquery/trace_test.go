@@ -0,0 +1,30 @@
+package xquery
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/heffcodex/xbun"
+	"github.com/heffcodex/xbun/xtrace"
+)
+
+func TestTableAttrs(t *testing.T) {
+	t.Parallel()
+
+	db := bun.NewDB(&sql.DB{}, pgdialect.New())
+	attr := xtrace.A("k", "v")
+
+	// The table is not looked up without a tracer.
+	require.Equal(t, []xtrace.Attr{attr}, tableAttrs[*testArticle](context.Background(), db, attr))
+
+	ctx := xtrace.ContextWithProvider(context.Background(), xtrace.Provider{Tracer: xtrace.NewRecorder()})
+	require.Equal(t, []xtrace.Attr{attr, xtrace.A(xtrace.AttrDBTable, "articles")}, tableAttrs[*testArticle](ctx, db, attr))
+
+	// Interface model types have no table.
+	require.Equal(t, []xtrace.Attr{attr}, tableAttrs[xbun.HasCompositePK](ctx, db, attr))
+}
@@ -0,0 +1,65 @@
+package xtrace
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/heffcodex/xbun/sqlnorm"
+)
+
+var _ bun.QueryHook = Hook{}
+
+// Hook is bun.QueryHook emitting a SpanQuery span along with the MetricQueryDuration and MetricQueryErrors metrics for every query.
+// Spans carry the sanitized statement (the literal values are replaced by placeholders), the operation and the model table name.
+// The provider is taken from the query context (see FromContext).
+type Hook struct{}
+
+type spanContextKey struct{}
+
+// BeforeQuery implements bun.QueryHook.
+func (Hook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	attrs := []Attr{
+		A(AttrDBStatement, sqlnorm.Normalize(event.Query)),
+		A(AttrDBOperation, event.Operation()),
+	}
+
+	if event.IQuery != nil {
+		if table := event.IQuery.GetTableName(); table != "" {
+			attrs = append(attrs, A(AttrDBTable, table))
+		}
+	}
+
+	ctx, span := StartSpan(ctx, SpanQuery, attrs...)
+
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// AfterQuery implements bun.QueryHook.
+func (Hook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	meter := FromContext(ctx).Meter
+	attrs := []Attr{A(AttrDBOperation, event.Operation())}
+
+	meter.Record(ctx, MetricQueryDuration, float64(time.Since(event.StartTime))/float64(time.Millisecond), attrs...)
+
+	span, ok := ctx.Value(spanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+
+	defer span.End()
+
+	if event.Result != nil {
+		if n, err := event.Result.RowsAffected(); err == nil {
+			span.SetAttributes(A(AttrDBRows, n))
+		}
+	}
+
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		span.RecordError(event.Err)
+		meter.Add(ctx, MetricQueryErrors, 1, attrs...)
+	}
+}
@@ -0,0 +1,181 @@
+package xtrace
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+var (
+	_ Tracer = (*Recorder)(nil)
+	_ Meter  = (*Recorder)(nil)
+	_ Span   = (*RecordedSpan)(nil)
+)
+
+// Recorder is the in-memory Tracer and Meter meant for tests. It's safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	spans   []*RecordedSpan
+	metrics []RecordedMetric
+}
+
+// RecordedSpan is the span started by Recorder.
+type RecordedSpan struct {
+	rec *Recorder
+
+	Name   string
+	Parent *RecordedSpan // nil for the root spans
+	Attrs  map[string]any
+	Errors []error
+	Ended  bool
+}
+
+// RecordedMetric is the metric value recorded by Recorder.
+type RecordedMetric struct {
+	Name    string
+	Counter bool // Meter.Add if true, Meter.Record otherwise
+	Value   float64
+	Attrs   map[string]any
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Provider returns the provider using the recorder as both the Tracer and the Meter.
+func (r *Recorder) Provider() Provider {
+	return Provider{Tracer: r, Meter: r}
+}
+
+// Context returns a copy of the context carrying the recorder provider (see ContextWithProvider).
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	return ContextWithProvider(ctx, r.Provider())
+}
+
+type recordedSpanContextKey struct{}
+
+// Start implements Tracer.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanContextKey{}).(*RecordedSpan)
+
+	span := &RecordedSpan{rec: r, Name: name, Parent: parent, Attrs: make(map[string]any, len(attrs))}
+	setAttrs(span.Attrs, attrs)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, recordedSpanContextKey{}, span), span
+}
+
+// Add implements Meter.
+func (r *Recorder) Add(_ context.Context, name string, value int64, attrs ...Attr) {
+	r.record(RecordedMetric{Name: name, Counter: true, Value: float64(value)}, attrs)
+}
+
+// Record implements Meter.
+func (r *Recorder) Record(_ context.Context, name string, value float64, attrs ...Attr) {
+	r.record(RecordedMetric{Name: name, Value: value}, attrs)
+}
+
+func (r *Recorder) record(m RecordedMetric, attrs []Attr) {
+	m.Attrs = make(map[string]any, len(attrs))
+	setAttrs(m.Attrs, attrs)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, m)
+}
+
+// Spans returns the copies of the started spans with the given name (or all of them if it's empty) in the start order.
+func (r *Recorder) Spans(name string) []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var spans []RecordedSpan
+
+	for _, s := range r.spans {
+		if name == "" || s.Name == name {
+			spans = append(spans, s.clone())
+		}
+	}
+
+	return spans
+}
+
+// Metrics returns the recorded values of the metric with the given name (or all of them if it's empty) in the record order.
+func (r *Recorder) Metrics(name string) []RecordedMetric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var metrics []RecordedMetric
+
+	for _, m := range r.metrics {
+		if name == "" || m.Name == name {
+			metrics = append(metrics, m)
+		}
+	}
+
+	return metrics
+}
+
+// Sum returns the sum of the recorded values of the metric.
+func (r *Recorder) Sum(name string) float64 {
+	var sum float64
+
+	for _, m := range r.Metrics(name) {
+		sum += m.Value
+	}
+
+	return sum
+}
+
+// Reset forgets the recorded spans and metrics.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans, r.metrics = nil, nil
+}
+
+// SetAttributes implements Span.
+func (s *RecordedSpan) SetAttributes(attrs ...Attr) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+
+	setAttrs(s.Attrs, attrs)
+}
+
+// RecordError implements Span.
+func (s *RecordedSpan) RecordError(err error) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+// End implements Span.
+func (s *RecordedSpan) End() {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+
+	s.Ended = true
+}
+
+// clone returns the copy of the span safe to read without the recorder lock.
+func (s *RecordedSpan) clone() RecordedSpan {
+	c := *s
+	c.Attrs = maps.Clone(s.Attrs)
+	c.Errors = append([]error(nil), s.Errors...)
+
+	return c
+}
+
+func setAttrs(dst map[string]any, attrs []Attr) {
+	for _, a := range attrs {
+		dst[a.Key] = a.Value
+	}
+}
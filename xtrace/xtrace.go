@@ -0,0 +1,157 @@
+// Package xtrace defines minimal tracing and metrics interfaces used to instrument xbun without a hard dependency on any telemetry SDK.
+// Adapt them to OpenTelemetry (or anything else) in production and use Recorder in tests.
+package xtrace
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Attribute keys set by the instrumentation of this module.
+const (
+	AttrDBStatement = "db.statement" // the query with the literal values replaced by placeholders
+	AttrDBOperation = "db.operation"
+	AttrDBTable     = "db.sql.table"
+	AttrDBRows      = "db.rows_affected"
+
+	AttrIterCursor    = "xbun.iter.cursor" // CursorSoft or CursorNative
+	AttrIterChunkSize = "xbun.iter.chunk_size"
+	AttrIterChunks    = "xbun.iter.chunks"
+	AttrIterRows      = "xbun.iter.rows"
+
+	AttrPaginatePage          = "xbun.paginate.page"
+	AttrPaginatePerPage       = "xbun.paginate.per_page"
+	AttrPaginateTotal         = "xbun.paginate.total"
+	AttrPaginateTotalPages    = "xbun.paginate.total_pages"
+	AttrPaginateEffectivePage = "xbun.paginate.effective_page"
+)
+
+// Cursor modes of the AttrIterCursor attribute.
+const (
+	CursorSoft   = "soft"
+	CursorNative = "native"
+)
+
+// Span and metric names emitted by the instrumentation of this module.
+const (
+	SpanQuery    = "xbun.query"
+	SpanIter     = "xbun.iter"
+	SpanPaginate = "xbun.paginate"
+
+	MetricQueryDuration = "xbun.query.duration" // milliseconds
+	MetricQueryErrors   = "xbun.query.errors"
+	MetricIterChunks    = "xbun.iter.chunks"
+	MetricIterChunkRows = "xbun.iter.chunk_rows"
+)
+
+// Attr is a span or metric attribute.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// A returns the attribute with the given key and value.
+func A(key string, value any) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts the span, which is the child of the one in the context if there is any.
+	// The returned context carries the started span.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+// Meter records metrics.
+type Meter interface {
+	// Add adds the value to the counter.
+	Add(ctx context.Context, name string, value int64, attrs ...Attr)
+
+	// Record records the value into the histogram.
+	Record(ctx context.Context, name string, value float64, attrs ...Attr)
+}
+
+// Provider provides the Tracer and the Meter. Nil ones are replaced with no-op implementations.
+type Provider struct {
+	Tracer Tracer
+	Meter  Meter
+}
+
+var defaultProvider atomic.Pointer[Provider]
+
+// SetDefault sets the provider used when there is none in the context (see ContextWithProvider).
+// By default, it's a no-op one.
+func SetDefault(p Provider) {
+	p = p.withDefaults()
+	defaultProvider.Store(&p)
+}
+
+type providerContextKey struct{}
+
+// ContextWithProvider returns a copy of the context carrying the provider, which overrides the default one for it.
+// It's mostly useful in parallel tests, each recording its own telemetry.
+func ContextWithProvider(ctx context.Context, p Provider) context.Context {
+	return context.WithValue(ctx, providerContextKey{}, p.withDefaults())
+}
+
+// FromContext returns the provider carried by the context or the default one.
+func FromContext(ctx context.Context) Provider {
+	if p, ok := ctx.Value(providerContextKey{}).(Provider); ok {
+		return p
+	}
+
+	if p := defaultProvider.Load(); p != nil {
+		return *p
+	}
+
+	return Provider{}.withDefaults()
+}
+
+// Tracing reports whether the provider has a tracer, i.e. the spans are not discarded.
+// It allows skipping the computation of the span attributes otherwise.
+func (p Provider) Tracing() bool {
+	_, ok := p.Tracer.(noop)
+	return p.Tracer != nil && !ok
+}
+
+// StartSpan starts the span with the provider from the context (see FromContext).
+func StartSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	return FromContext(ctx).Tracer.Start(ctx, name, attrs...)
+}
+
+func (p Provider) withDefaults() Provider {
+	if p.Tracer == nil {
+		p.Tracer = noop{}
+	}
+
+	if p.Meter == nil {
+		p.Meter = noop{}
+	}
+
+	return p
+}
+
+// -----------------------------------------------------------------------------------------------------------------------------------------
+
+var (
+	_ Tracer = noop{}
+	_ Span   = noop{}
+	_ Meter  = noop{}
+)
+
+// noop is the Tracer, Span and Meter doing nothing.
+type noop struct{}
+
+func (n noop) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) { return ctx, n }
+func (noop) SetAttributes(...Attr)                                                    {}
+func (noop) RecordError(error)                                                        {}
+func (noop) End()                                                                     {}
+func (noop) Add(context.Context, string, int64, ...Attr)                              {}
+func (noop) Record(context.Context, string, float64, ...Attr)                         {}
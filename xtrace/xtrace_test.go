@@ -0,0 +1,31 @@
+package xtrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	p := FromContext(context.Background())
+	require.NotNil(t, p.Tracer)
+	require.NotNil(t, p.Meter)
+	require.False(t, p.Tracing())
+
+	// No-op implementations must be safe to use.
+	ctx, span := StartSpan(context.Background(), "noop", A("k", "v"))
+	span.SetAttributes(A("k", "v"))
+	span.RecordError(nil)
+	span.End()
+	p.Meter.Add(ctx, "counter", 1)
+	p.Meter.Record(ctx, "histogram", 1)
+
+	rec := NewRecorder()
+	p = FromContext(ContextWithProvider(context.Background(), Provider{Tracer: rec}))
+	require.Same(t, rec, p.Tracer)
+	require.NotNil(t, p.Meter)
+	require.True(t, p.Tracing())
+}